@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dakshpareek/ctx/internal/config"
+	"github.com/dakshpareek/ctx/internal/display"
+	"github.com/dakshpareek/ctx/internal/index"
+	"github.com/dakshpareek/ctx/internal/skeleton"
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+func newApplyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply [response-file]",
+		Short: "Save skeletons from an AI response and mark them current",
+		Long: `Run this after your AI assistant answers the prompt from 'ctx ask'.
+
+ctx apply will:
+  1. Read the response from the given file, or stdin when omitted or "-"
+  2. Split it into skeleton blocks using the **Skeleton Path:** markers
+  3. Write each skeleton, record its hash, and mark the entry current`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source := "-"
+			if len(args) == 1 {
+				source = args[0]
+			}
+			return withWorkspaceLock(cmd.CommandPath(), func() error {
+				return runApply(source, cmd.InOrStdin())
+			})
+		},
+	}
+
+	return cmd
+}
+
+func runApply(source string, stdin io.Reader) error {
+	ctxDir, indexPath, err := ensureWorkspace(true)
+	if err != nil {
+		return err
+	}
+	wd := filepath.Dir(ctxDir)
+
+	var data []byte
+	if source == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("read response: %w", err)}
+	}
+
+	blocks := skeleton.ParseResponse(string(data))
+	if len(blocks) == 0 {
+		return &types.Error{Code: types.ExitCodeUserError, Err: fmt.Errorf("no %s markers found in response", skeleton.PathMarker)}
+	}
+
+	idx, err := index.LoadIndex(indexPath)
+	if err != nil {
+		return &types.Error{Code: types.ExitCodeData, Err: err}
+	}
+
+	cfg, err := config.LoadConfig(filepath.Join(ctxDir, configFileName))
+	if err != nil {
+		return &types.Error{Code: types.ExitCodeData, Err: err}
+	}
+	root := sourceRoot(wd, *cfg)
+
+	bySkeleton := make(map[string]string, len(idx.Files))
+	for path, entry := range idx.Files {
+		skelPath := entry.SkeletonPath
+		if skelPath == "" {
+			skelPath = skeleton.PathForSource(path)
+		}
+		bySkeleton[skelPath] = path
+	}
+
+	var (
+		applied []string
+		skipped []string
+	)
+
+	for _, block := range blocks {
+		path, ok := bySkeleton[block.SkeletonPath]
+		if !ok {
+			fmt.Println(display.Warning("Skipping %s: not a tracked skeleton path", block.SkeletonPath))
+			skipped = append(skipped, block.SkeletonPath)
+			continue
+		}
+		if block.Content == "" {
+			fmt.Println(display.Warning("Skipping %s: empty skeleton", block.SkeletonPath))
+			skipped = append(skipped, block.SkeletonPath)
+			continue
+		}
+
+		entry := idx.Files[path]
+		entry.Path = path
+		entry.SkeletonPath = block.SkeletonPath
+		entry, err = saveSkeleton(wd, root, entry, block.Content)
+		if err != nil {
+			return err
+		}
+		idx.Files[path] = entry
+		applied = append(applied, path)
+		if entry.Status == types.StatusStale {
+			fmt.Println(display.Warning("%s changed after its prompt was built; the skeleton stays stale until it is regenerated", path))
+		}
+	}
+
+	if len(applied) == 0 {
+		return &types.Error{Code: types.ExitCodeUserError, Err: fmt.Errorf("no skeletons applied (%d block(s) skipped)", len(skipped))}
+	}
+
+	idx.Stats = index.CalculateStats(idx)
+	if err := index.SaveIndex(idx, indexPath); err != nil {
+		return &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+	}
+
+	sort.Strings(applied)
+	fmt.Println(display.Success("Applied %d skeleton(s)", len(applied)))
+	for _, path := range applied {
+		fmt.Printf("  - %s\n", path)
+	}
+	if len(skipped) > 0 {
+		fmt.Println(display.Warning("%d block(s) skipped", len(skipped)))
+	}
+
+	remaining := idx.Stats.Stale + idx.Stats.Missing + idx.Stats.PendingGeneration
+	if remaining == 0 {
+		fmt.Println(display.Success("All caught up! Run 'ctx bundle' to export context."))
+	} else {
+		fmt.Println(display.Info("%d file(s) still need skeletons. Run 'ctx status -v' to see them.", remaining))
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dakshpareek/ctx/internal/hash"
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+func TestApplyWritesSkeletonsFromResponseFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "main.go", "package main\n")
+	writeTempFile(t, dir, "util.go", "package main\n")
+	_, _ = executeCommand(t, dir, "init")
+	_ = execAndCaptureStdout(t, dir, "ask", "--no-local", "--quiet")
+
+	response := "**Skeleton Path:** .ctx/skeletons/main.skeleton.go\n```\n**main**\n```\n\n" +
+		"**Skeleton Path:** .ctx/skeletons/unknown.skeleton.go\n```\n**unknown**\n```\n"
+	writeTempFile(t, dir, "response.md", response)
+
+	stdout := execAndCaptureStdout(t, dir, "apply", "response.md")
+	if !strings.Contains(stdout, "Applied 1 skeleton(s)") {
+		t.Fatalf("expected apply summary, got:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, "not a tracked skeleton path") {
+		t.Fatalf("expected warning for unknown path, got:\n%s", stdout)
+	}
+
+	idx := loadIndex(t, dir)
+	entry := idx.Files["main.go"]
+	if entry.Status != types.StatusCurrent {
+		t.Fatalf("expected main.go current, got %s", entry.Status)
+	}
+	if entry.SkeletonHash != hash.HashContent([]byte("**main**\n")) {
+		t.Fatalf("unexpected skeleton hash %s", entry.SkeletonHash)
+	}
+	if status := idx.Files["util.go"].Status; status != types.StatusPendingGeneration {
+		t.Fatalf("expected util.go to remain pending, got %s", status)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, entry.SkeletonPath))
+	if err != nil {
+		t.Fatalf("read skeleton: %v", err)
+	}
+	if string(data) != "**main**\n" {
+		t.Fatalf("unexpected skeleton content %q", data)
+	}
+}
+
+func TestApplyLeavesSyncTimeAlone(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "main.go", "package main\n")
+	_, _ = executeCommand(t, dir, "init")
+	_ = execAndCaptureStdout(t, dir, "ask", "--no-local", "--quiet")
+	before := loadIndex(t, dir).LastSync
+
+	writeTempFile(t, dir, "response.md", "**Skeleton Path:** .ctx/skeletons/main.skeleton.go\n```\n**main**\n```\n")
+	_ = execAndCaptureStdout(t, dir, "apply", "response.md")
+
+	if after := loadIndex(t, dir).LastSync; !after.Equal(before) {
+		t.Fatalf("expected apply to keep the last sync time %s, got %s", before, after)
+	}
+}
+
+func TestApplyReadsStdin(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "main.go", "package main\n")
+	_, _ = executeCommand(t, dir, "init")
+
+	cleanup := changeDir(t, dir)
+	defer cleanup()
+
+	response := "**Skeleton Path:** .ctx/skeletons/main.skeleton.go\n```\n**main**\n```\n"
+	_ = captureOutput(t, func() {
+		if err := runApply("-", strings.NewReader(response)); err != nil {
+			t.Fatalf("runApply: %v", err)
+		}
+	})
+
+	idx := loadIndex(t, dir)
+	if status := idx.Files["main.go"].Status; status != types.StatusCurrent {
+		t.Fatalf("expected main.go current, got %s", status)
+	}
+}
+
+func TestApplyWithoutMarkers(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "main.go", "package main\n")
+	_, _ = executeCommand(t, dir, "init")
+
+	_, _, err := executeCommandAllowError(t, dir, "apply", "missing.md")
+	if err == nil {
+		t.Fatalf("expected error for missing response file")
+	}
+
+	writeTempFile(t, dir, "response.md", "nothing useful")
+	_, _, err = executeCommandAllowError(t, dir, "apply", "response.md")
+	if err == nil || !strings.Contains(err.Error(), "no **Skeleton Path:** markers") {
+		t.Fatalf("expected marker error, got %v", err)
+	}
+}
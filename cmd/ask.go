@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,7 +14,12 @@ import (
 )
 
 type askOptions struct {
-	quiet bool
+	quiet      bool
+	noLocal    bool
+	maxTokens  int
+	maxBatches int
+	diff       bool
+	jobs       int
 }
 
 func newAskCmd() *cobra.Command {
@@ -30,22 +36,30 @@ ctx ask will:
   3. Generate a prompt saved to .ctx/prompt.md (and print it unless --quiet)
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runAsk(opts)
+			return withWorkspaceLock(cmd.CommandPath(), func() error {
+				return runAsk(cmd.Context(), opts)
+			})
 		},
 	}
 
 	cmd.Flags().BoolVarP(&opts.quiet, "quiet", "q", false, "Do not print the generated prompt to stdout")
+	cmd.Flags().BoolVar(&opts.noLocal, "no-local", false, "Prompt for every file instead of generating skeletons locally where an extractor is registered")
+	addDeprecatedLocalFlag(cmd)
+	cmd.Flags().IntVar(&opts.maxTokens, "max-tokens", 0, "Split the prompt into numbered files of at most this many estimated tokens")
+	cmd.Flags().IntVar(&opts.maxBatches, "max-batches", 0, "Only emit this many batches; remaining files keep their status")
+	cmd.Flags().BoolVar(&opts.diff, "diff", false, "Send the previous skeleton plus a source diff for changed files instead of the full source")
+	cmd.Flags().IntVar(&opts.jobs, "jobs", 0, "Number of files to scan and hash in parallel (default: number of CPUs)")
 
 	return cmd
 }
 
-func runAsk(opts askOptions) error {
+func runAsk(ctx context.Context, opts askOptions) error {
 	ctxDir, _, err := ensureWorkspace(false)
 	if err != nil {
 		return err
 	}
 
-	if err := runSync(syncOptions{}); err != nil {
+	if err := runSync(syncOptions{jobs: opts.jobs}); err != nil {
 		return err
 	}
 
@@ -79,30 +93,49 @@ func runAsk(opts askOptions) error {
 
 	promptPath := filepath.Join(ctxDir, "prompt.md")
 	genOpts := generateOptions{
-		filter: "pending,stale,missing",
-		output: promptPath,
-		quiet:  opts.quiet,
+		filter:  "pending,stale,missing",
+		output:  promptPath,
+		quiet:   opts.quiet,
+		noLocal: opts.noLocal,
+
+		maxTokens:  opts.maxTokens,
+		maxBatches: opts.maxBatches,
+		diff:       opts.diff,
 	}
 
-	if err := runGenerate(genOpts); err != nil {
+	result, err := generateSkeletons(ctx, genOpts)
+	if err != nil {
 		return err
 	}
 
+	if len(result.outputPaths) == 0 {
+		fmt.Println()
+		fmt.Println(display.Success("All selected skeletons were generated locally. No prompt needed."))
+		fmt.Println(display.Info("Next: run 'ctx status' to inspect the index, or 'ctx bundle' to export context."))
+		return nil
+	}
+
 	fmt.Println()
-	fmt.Println(display.Success("Prompt saved to %s", promptPath))
-	fmt.Println(display.Info("Next steps: 1) share the prompt with your AI assistant, 2) save skeletons under .ctx/skeletons/, 3) run 'ctx update'."))
+	for _, path := range result.outputPaths {
+		fmt.Println(display.Success("Prompt saved to %s", path))
+	}
+	fmt.Println(display.Info("Next steps: 1) share the prompt with your AI assistant, 2) save the response and run 'ctx apply <response-file>' (or save skeletons under .ctx/skeletons/ and run 'ctx update')."))
 
 	if opts.quiet {
 		return nil
 	}
 
-	data, err := os.ReadFile(promptPath)
+	data, err := os.ReadFile(result.outputPaths[0])
 	if err != nil {
 		return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("read prompt: %w", err)}
 	}
 
 	fmt.Println()
-	fmt.Println(display.Bold("Prompt Preview:"))
+	if len(result.outputPaths) > 1 {
+		fmt.Println(display.Bold("Prompt Preview (batch 1 of %d):", len(result.outputPaths)))
+	} else {
+		fmt.Println(display.Bold("Prompt Preview:"))
+	}
 	fmt.Println(string(data))
 
 	return nil
@@ -12,7 +12,7 @@ func TestAskCreatesDefaultPromptAndPrintsSummary(t *testing.T) {
 	writeTempFile(t, dir, "main.go", "package main\n")
 	_, _ = executeCommand(t, dir, "init")
 
-	stdout := execAndCaptureStdout(t, dir, "ask")
+	stdout := execAndCaptureStdout(t, dir, "ask", "--no-local")
 
 	promptPath := filepath.Join(dir, ".ctx", "prompt.md")
 	data, err := os.ReadFile(promptPath)
@@ -36,7 +36,7 @@ func TestAskQuietSuppressesPromptBody(t *testing.T) {
 	writeTempFile(t, dir, "main.go", "package main\n")
 	_, _ = executeCommand(t, dir, "init")
 
-	stdout := execAndCaptureStdout(t, dir, "ask", "--quiet")
+	stdout := execAndCaptureStdout(t, dir, "ask", "--no-local", "--quiet")
 
 	if strings.Contains(stdout, "Code Context Skeleton Generation") {
 		t.Fatalf("expected quiet mode to suppress prompt body, got:\n%s", stdout)
@@ -56,13 +56,13 @@ func TestAskWhenAllCurrent(t *testing.T) {
 	writeTempFile(t, dir, "main.go", "package main\n")
 	_, _ = executeCommand(t, dir, "init")
 
-	_, _ = executeCommand(t, dir, "ask", "--quiet")
+	_, _ = executeCommand(t, dir, "ask", "--no-local", "--quiet")
 	idx := loadIndex(t, dir)
 	entry := idx.Files["main.go"]
 	writeTempFile(t, dir, entry.SkeletonPath, "** skeleton **\n")
 	_, _ = executeCommand(t, dir, "update")
 
-	stdout := execAndCaptureStdout(t, dir, "ask", "--quiet")
+	stdout := execAndCaptureStdout(t, dir, "ask", "--no-local", "--quiet")
 	if !strings.Contains(stdout, "All skeletons are current") {
 		t.Fatalf("expected success message when nothing needs generation:\n%s", stdout)
 	}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dakshpareek/ctx/internal/display"
+	"github.com/dakshpareek/ctx/internal/skeleton"
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+type promptBatch struct {
+	paths  []string
+	output string
+}
+
+// buildPromptBatches splits paths into prompts whose estimated size stays within maxTokens.
+// Paths are ordered by template first; every batch repeats the scaffold and the templates its files use.
+// The scaffold is measured once and each file adds its own section, plus its template when it starts a
+// new group, so sizing stays linear in the number of files. A maxTokens of zero or less produces a single batch.
+func buildPromptBatches(paths []string, idx *types.Index, templates *skeleton.PromptTemplates, sources map[string]promptSource, maxTokens int) ([]promptBatch, error) {
+	if maxTokens <= 0 {
+		output, err := renderPrompt(paths, idx, templates, sources)
+		if err != nil {
+			return nil, err
+		}
+		return []promptBatch{{paths: paths, output: output}}, nil
+	}
+
+	empty, err := renderPrompt(nil, idx, templates, sources)
+	if err != nil {
+		return nil, err
+	}
+	overhead := skeleton.EstimateTokens(empty)
+
+	var (
+		batches []promptBatch
+		current []string
+		used    int
+		group   string
+	)
+	flush := func() error {
+		output, err := renderPrompt(current, idx, templates, sources)
+		if err != nil {
+			return err
+		}
+		batches = append(batches, promptBatch{paths: current, output: output})
+		current = nil
+		return nil
+	}
+
+	for _, path := range orderByTemplate(paths, idx, templates) {
+		cost, err := measurePromptFile(path, idx, templates, sources, overhead)
+		if err != nil {
+			return nil, err
+		}
+
+		tokens := cost.section
+		if len(current) == 0 || cost.group != group {
+			tokens += cost.template
+		}
+		if len(current) > 0 && used+tokens > maxTokens {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			tokens = cost.section + cost.template
+		}
+		if len(current) == 0 {
+			used = overhead
+			if used+tokens > maxTokens {
+				fmt.Println(display.Warning("%s alone exceeds the %d token budget (~%d tokens); it gets its own batch", path, maxTokens, used+tokens))
+			}
+		}
+
+		current = append(current, path)
+		used += tokens
+		group = cost.group
+	}
+	if len(current) > 0 {
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+
+	return batches, nil
+}
+
+type promptFileCost struct {
+	group    string
+	template int
+	section  int
+}
+
+// measurePromptFile estimates what one file adds to a prompt: the rendered template it shares with
+// adjacent files of the same group, and its own section on top of the scaffold overhead.
+func measurePromptFile(path string, idx *types.Index, templates *skeleton.PromptTemplates, sources map[string]promptSource, overhead int) (promptFileCost, error) {
+	file := promptFileData(1, path, idx, sources[path])
+	name, text := templates.Lookup(path, file.Type)
+	rendered, err := skeleton.RenderTemplate(name, text, file)
+	if err != nil {
+		return promptFileCost{}, &types.Error{Code: types.ExitCodeData, Err: err}
+	}
+	output, err := renderPrompt([]string{path}, idx, templates, sources)
+	if err != nil {
+		return promptFileCost{}, err
+	}
+
+	cost := promptFileCost{group: name + "\x00" + rendered, template: skeleton.EstimateTokens(rendered)}
+	cost.section = skeleton.EstimateTokens(output) - overhead - cost.template
+	if cost.section < 0 {
+		cost.section = 0
+	}
+	return cost, nil
+}
+
+// batchOutputPath numbers an output path for a batch: prompt.md becomes prompt-001.md.
+func batchOutputPath(base string, number int) string {
+	ext := filepath.Ext(base)
+	return fmt.Sprintf("%s-%03d%s", strings.TrimSuffix(base, ext), number, ext)
+}
+
+// removeBatchOutputs deletes numbered batch files left next to base by an earlier run,
+// so a run that emits fewer batches does not leave prompts for files it no longer covers.
+func removeBatchOutputs(base string) error {
+	ext := filepath.Ext(base)
+	prefix := filepath.Base(strings.TrimSuffix(base, ext)) + "-"
+	entries, err := os.ReadDir(filepath.Dir(base))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		number := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if len(number) < 3 || strings.Trim(number, "0123456789") != "" {
+			continue
+		}
+		if err := os.Remove(filepath.Join(filepath.Dir(base), name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dakshpareek/ctx/internal/skeleton"
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+func TestBuildPromptBatchesSplitsByBudget(t *testing.T) {
+	dir := t.TempDir()
+	idx := &types.Index{Files: map[string]types.FileEntry{}}
+	paths := []string{"a.go", "b.go", "c.go"}
+	for _, path := range paths {
+		writeTempFile(t, dir, path, "package main\n"+strings.Repeat("// filler line\n", 40))
+		idx.Files[path] = types.FileEntry{Path: path, SkeletonPath: skeleton.PathForSource(path), Status: types.StatusMissing}
+	}
+
+	templates := skeleton.NewPromptTemplates("template", nil)
+	sources, err := readPromptSources(paths, idx, dir, dir, false)
+	if err != nil {
+		t.Fatalf("readPromptSources error: %v", err)
+	}
+
+	single, err := buildPromptBatches(paths, idx, templates, sources, 0)
+	if err != nil {
+		t.Fatalf("buildPromptBatches error: %v", err)
+	}
+	if len(single) != 1 || len(single[0].paths) != 3 {
+		t.Fatalf("expected a single batch without a budget, got %d", len(single))
+	}
+
+	empty, err := renderPrompt(nil, idx, templates, nil)
+	if err != nil {
+		t.Fatalf("renderPrompt error: %v", err)
+	}
+	overhead := skeleton.EstimateTokens(empty)
+	fileCost := (skeleton.EstimateTokens(single[0].output) - overhead) / 3
+	batches, err := buildPromptBatches(paths, idx, templates, sources, overhead+fileCost*5/2)
+	if err != nil {
+		t.Fatalf("buildPromptBatches error: %v", err)
+	}
+	if len(batches) != 2 {
+		t.Fatalf("expected 2 batches, got %d", len(batches))
+	}
+	for i, batch := range batches {
+		if !strings.Contains(batch.output, "## Skeleton Generation Template") {
+			t.Fatalf("expected batch %d to repeat the template", i+1)
+		}
+		if !strings.Contains(batch.output, "### File 1: "+batch.paths[0]) {
+			t.Fatalf("expected batch %d numbering to restart", i+1)
+		}
+	}
+	if got := len(batches[0].paths) + len(batches[1].paths); got != 3 {
+		t.Fatalf("expected every file in a batch, got %d", got)
+	}
+}
+
+func TestBatchOutputPath(t *testing.T) {
+	if got := batchOutputPath(filepath.Join(".ctx", "prompt.md"), 2); got != filepath.Join(".ctx", "prompt-002.md") {
+		t.Fatalf("unexpected batch path %q", got)
+	}
+	if got := batchOutputPath("prompt", 12); got != "prompt-012" {
+		t.Fatalf("unexpected batch path %q", got)
+	}
+}
+
+func TestRemoveBatchOutputs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"prompt.md", "prompt-001.md", "prompt-004.md", "prompt-notes.md", "other-001.md"} {
+		writeTempFile(t, dir, name, "x")
+	}
+
+	if err := removeBatchOutputs(filepath.Join(dir, "prompt.md")); err != nil {
+		t.Fatalf("removeBatchOutputs error: %v", err)
+	}
+	for name, kept := range map[string]bool{"prompt.md": true, "prompt-001.md": false, "prompt-004.md": false, "prompt-notes.md": true, "other-001.md": true} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if kept && err != nil {
+			t.Fatalf("expected %s to be kept: %v", name, err)
+		}
+		if !kept && !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed", name)
+		}
+	}
+}
+
+func TestGenerateMaxBatchesMarksOnlyEmittedFiles(t *testing.T) {
+	dir := t.TempDir()
+	body := "package main\n" + strings.Repeat("// filler line for the token budget\n", 60)
+	writeTempFile(t, dir, "a.go", body)
+	writeTempFile(t, dir, "b.go", body)
+	writeTempFile(t, dir, "c.go", body)
+	_, _ = executeCommand(t, dir, "init")
+
+	stdout := execAndCaptureStdout(t, dir, "generate", "--no-local", "--max-tokens", "1200", "--max-batches", "1", "--quiet")
+	if !strings.Contains(stdout, "left for later batches") {
+		t.Fatalf("expected deferred files notice, got:\n%s", stdout)
+	}
+
+	idx := loadIndex(t, dir)
+	pending := 0
+	for _, entry := range idx.Files {
+		if entry.Status == types.StatusPendingGeneration {
+			pending++
+		}
+	}
+	if pending == 0 || pending == 3 {
+		t.Fatalf("expected only the first batch marked pending, got %d", pending)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".ctx", "prompt-001.md")); err != nil {
+		t.Fatalf("expected numbered prompt file: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".ctx", "prompt-002.md")); !os.IsNotExist(err) {
+		t.Fatalf("expected deferred batch not to be written")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/dakshpareek/ctx/internal/display"
 	"github.com/dakshpareek/ctx/internal/fs"
 	"github.com/dakshpareek/ctx/internal/index"
+	"github.com/dakshpareek/ctx/internal/skeleton"
 	"github.com/dakshpareek/ctx/internal/types"
 )
 
@@ -20,7 +21,7 @@ func newCleanCmd() *cobra.Command {
 		Use:   "clean",
 		Short: "Remove orphaned skeleton files",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runClean()
+			return withWorkspaceLock(cmd.CommandPath(), runClean)
 		},
 	}
 }
@@ -49,8 +50,12 @@ func runClean() error {
 	fmt.Println("Cleaning orphaned skeletons...")
 
 	skeletonDir := filepath.Join(ctxDir, "skeletons")
+	snapshotDir := filepath.Join(wd, filepath.FromSlash(skeleton.SnapshotDirRoot))
 	referenced := make(map[string]struct{}, len(idx.Files))
 	for _, entry := range idx.Files {
+		if entry.SourceSnapshotHash != "" {
+			referenced[filepath.Join(wd, filepath.FromSlash(skeleton.SnapshotPathForSource(entry.Path)))] = struct{}{}
+		}
 		if entry.SkeletonPath == "" {
 			continue
 		}
@@ -63,25 +68,11 @@ func runClean() error {
 		dirs     []string
 	)
 
-	filepath.WalkDir(skeletonDir, func(path string, d stdfs.DirEntry, walkErr error) error {
-		if walkErr != nil {
-			return walkErr
-		}
-		if d.IsDir() {
-			if path != skeletonDir {
-				dirs = append(dirs, path)
-			}
-			return nil
-		}
-
-		if _, ok := referenced[path]; !ok {
-			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-				return err
-			}
-			orphaned++
-		}
-		return nil
-	})
+	for _, root := range []string{skeletonDir, snapshotDir} {
+		removed, rootDirs := removeOrphans(root, referenced)
+		orphaned += removed
+		dirs = append(dirs, rootDirs...)
+	}
 
 	// Remove directories deepest first.
 	sort.Slice(dirs, func(i, j int) bool {
@@ -101,3 +92,33 @@ func runClean() error {
 
 	return nil
 }
+
+// removeOrphans deletes files under root that are not referenced and returns the count plus the subdirectories seen.
+func removeOrphans(root string, referenced map[string]struct{}) (int, []string) {
+	var (
+		orphaned int
+		dirs     []string
+	)
+
+	filepath.WalkDir(root, func(path string, d stdfs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if d.IsDir() {
+			if path != root {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+
+		if _, ok := referenced[path]; !ok {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			orphaned++
+		}
+		return nil
+	})
+
+	return orphaned, dirs
+}
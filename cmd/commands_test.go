@@ -109,3 +109,82 @@ func TestRunExportStdout(t *testing.T) {
 		t.Fatalf("expected json output")
 	}
 }
+
+func TestValidateStagedIgnoresUnstagedEdits(t *testing.T) {
+	dir := initGitRepo(t)
+	writeTempFile(t, dir, "api/user.go", "package api\n\n// User is an account.\ntype User struct{}\n")
+	writeTempFile(t, dir, "api/order.go", "package api\n\n// Order is a purchase.\ntype Order struct{}\n")
+	commitAll(t, dir, "init")
+	_, _ = executeCommand(t, dir, "init")
+	_ = execAndCaptureStdout(t, dir, "generate", "--local", "--quiet")
+
+	validateStaged := func() (string, error) {
+		var err error
+		stdout := captureOutput(t, func() {
+			_, _, err = executeCommandAllowError(t, dir, "validate", "--strict", "--staged")
+		})
+		return stdout, err
+	}
+
+	writeTempFile(t, dir, "api/order.go", "package api\n\n// Order is a purchase.\ntype Order struct{ Total int }\n")
+	if stdout, err := validateStaged(); err != nil {
+		t.Fatalf("expected unstaged edits to be ignored, got %v:\n%s", err, stdout)
+	}
+
+	writeTempFile(t, dir, "api/user.go", "package api\n\n// User is an account.\ntype User struct{ Name string }\n")
+	if err := runGitCommand(dir, "add", "api/user.go"); err != nil {
+		t.Skipf("git add failed: %v", err)
+	}
+	stdout, err := validateStaged()
+	if err == nil || !strings.Contains(stdout, "api/user.go: source hash mismatch") || strings.Contains(stdout, "api/order.go") {
+		t.Fatalf("expected only the staged file to fail, got %v:\n%s", err, stdout)
+	}
+
+	// Syncing records the new hash, but the skeleton still describes the old source.
+	_ = execAndCaptureStdout(t, dir, "sync")
+	stdout, err = validateStaged()
+	if err == nil || !strings.Contains(stdout, "api/user.go: skeleton is stale") {
+		t.Fatalf("expected the stale skeleton to fail validation, got %v:\n%s", err, stdout)
+	}
+
+	// Regenerating the skeleton lets the commit through while api/order.go stays edited and unstaged.
+	_ = execAndCaptureStdout(t, dir, "generate", "--local", "--quiet")
+	if stdout, err := validateStaged(); err != nil {
+		t.Fatalf("expected regenerated skeleton to pass, got %v:\n%s", err, stdout)
+	}
+}
+
+func TestValidateStagedReportsUnindexedSources(t *testing.T) {
+	dir := initGitRepo(t)
+	writeTempFile(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+	writeTempFile(t, dir, ".ctxignore", "scratch/\n")
+	commitAll(t, dir, "init")
+	_, _ = executeCommand(t, dir, "init")
+	_ = execAndCaptureStdout(t, dir, "generate", "--quiet")
+
+	writeTempFile(t, dir, "scratch/tmp.go", "package scratch\n")
+	writeTempFile(t, dir, "new.go", "package main\n\nfunc New() {}\n")
+	if err := runGitCommand(dir, "add", "scratch/tmp.go", "new.go"); err != nil {
+		t.Skipf("git add failed: %v", err)
+	}
+
+	var err error
+	stdout := captureOutput(t, func() {
+		_, _, err = executeCommandAllowError(t, dir, "validate", "--strict", "--staged")
+	})
+	if err == nil || !strings.Contains(stdout, "new.go: not indexed") {
+		t.Fatalf("expected the new source to fail validation, got %v:\n%s", err, stdout)
+	}
+	if strings.Contains(stdout, "scratch/tmp.go") {
+		t.Fatalf("expected ignored files to be skipped:\n%s", stdout)
+	}
+
+	_ = execAndCaptureStdout(t, dir, "sync")
+	_ = execAndCaptureStdout(t, dir, "generate", "--quiet")
+	stdout = captureOutput(t, func() {
+		_, _, err = executeCommandAllowError(t, dir, "validate", "--strict", "--staged")
+	})
+	if err != nil {
+		t.Fatalf("expected validation to pass once new.go has a skeleton, got %v:\n%s", err, stdout)
+	}
+}
@@ -20,6 +20,7 @@ import (
 type exportOptions struct {
 	format string
 	output string
+	rev    string
 }
 
 func newExportCmd() *cobra.Command {
@@ -39,6 +40,7 @@ func newExportCmd() *cobra.Command {
 
 	cmd.Flags().StringVar(&opts.format, "format", opts.format, "output format: markdown or json")
 	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "write export to file instead of stdout")
+	cmd.Flags().StringVar(&opts.rev, "rev", "", "export the skeletons that are current for a git revision instead of the working tree")
 
 	return cmd
 }
@@ -63,6 +65,12 @@ func runExport(opts exportOptions) error {
 		return &types.Error{Code: types.ExitCodeData, Err: err}
 	}
 
+	if opts.rev != "" {
+		if _, err := syncToRevision(wd, ctxDir, idx, opts.rev); err != nil {
+			return err
+		}
+	}
+
 	currentPaths := currentSkeletonPaths(idx)
 	if len(currentPaths) == 0 {
 		return &types.Error{Code: types.ExitCodeUserError, Err: fmt.Errorf("no current skeletons to export")}
@@ -104,6 +112,7 @@ func runExport(opts exportOptions) error {
 
 type exportedSkeleton struct {
 	Path         string
+	Package      string `json:",omitempty"`
 	SkeletonPath string
 	Type         string
 	Status       types.Status
@@ -140,6 +149,7 @@ func readSkeletonContents(paths []string, idx *types.Index, cwd string) ([]expor
 
 		result = append(result, exportedSkeleton{
 			Path:         path,
+			Package:      entry.Package,
 			SkeletonPath: entry.SkeletonPath,
 			Type:         entry.Type,
 			Status:       entry.Status,
@@ -166,9 +176,25 @@ func buildMarkdownExport(idx *types.Index, skeletons []exportedSkeleton, generat
 	builder.WriteString(fmt.Sprintf("- Missing skeletons: %d\n", idx.Stats.Missing))
 	builder.WriteString(fmt.Sprintf("- Pending generation: %d\n\n", idx.Stats.PendingGeneration))
 
+	if len(idx.PackageStats) > 0 {
+		builder.WriteString("## Packages\n\n")
+		for _, name := range sortedPackages(idx.PackageStats) {
+			builder.WriteString(fmt.Sprintf("- %s: %s\n", name, describePackageStats(idx.PackageStats[name])))
+		}
+		builder.WriteString("\n")
+
+		skeletons = append([]exportedSkeleton(nil), skeletons...)
+		sort.SliceStable(skeletons, func(i, j int) bool {
+			return skeletons[i].Package < skeletons[j].Package
+		})
+	}
+
 	builder.WriteString("## Skeletons\n\n")
 	for i, skel := range skeletons {
 		builder.WriteString(fmt.Sprintf("### %s\n", skel.Path))
+		if skel.Package != "" {
+			builder.WriteString(fmt.Sprintf("**Package:** %s\n", skel.Package))
+		}
 		builder.WriteString(fmt.Sprintf("**Skeleton Path:** %s\n", skel.SkeletonPath))
 		if skel.Type != "" {
 			builder.WriteString(fmt.Sprintf("**Type:** %s\n", skel.Type))
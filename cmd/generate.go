@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,10 +11,11 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/dakshpareek/ctx/internal/config"
+	"github.com/dakshpareek/ctx/internal/diff"
 	"github.com/dakshpareek/ctx/internal/display"
 	"github.com/dakshpareek/ctx/internal/fs"
 	"github.com/dakshpareek/ctx/internal/index"
-	"github.com/dakshpareek/ctx/internal/scanner"
 	"github.com/dakshpareek/ctx/internal/skeleton"
 	"github.com/dakshpareek/ctx/internal/types"
 )
@@ -23,6 +25,24 @@ type generateOptions struct {
 	files  string
 	output string
 	quiet  bool
+	// noLocal sends every file to the prompt, even those a registered extractor could handle.
+	noLocal bool
+
+	send        bool
+	dryRun      bool
+	concurrency int
+
+	maxTokens  int
+	maxBatches int
+	diff       bool
+}
+
+type generateResult struct {
+	promptFiles   []string
+	deferredFiles []string
+	localFiles    []string
+	sentFiles     []string
+	outputPaths   []string
 }
 
 func newGenerateCmd() *cobra.Command {
@@ -36,7 +56,9 @@ func newGenerateCmd() *cobra.Command {
 		Long:  advancedDescription,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			printAdvancedNotice("ctx ask")
-			return runGenerate(opts)
+			return withWorkspaceLock(cmd.CommandPath(), func() error {
+				return runGenerate(cmd.Context(), opts)
+			})
 		},
 	}
 
@@ -44,38 +66,59 @@ func newGenerateCmd() *cobra.Command {
 	cmd.Flags().StringVar(&opts.files, "files", "", "comma-separated list of specific files to include")
 	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "write prompt to a specific file")
 	cmd.Flags().BoolVarP(&opts.quiet, "quiet", "q", false, "Suppress prompt body (still writes to file)")
+	cmd.Flags().BoolVar(&opts.noLocal, "no-local", false, "prompt for every file instead of generating skeletons locally where an extractor is registered")
+	addDeprecatedLocalFlag(cmd)
+	cmd.Flags().BoolVar(&opts.send, "send", false, "send per-file prompts to the provider configured in config.json and save the replies")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "show which files would be processed without changing anything")
+	cmd.Flags().IntVar(&opts.concurrency, "concurrency", 0, "maximum parallel provider requests (default from config, or 4)")
+	cmd.Flags().IntVar(&opts.maxTokens, "max-tokens", 0, "split the prompt into numbered files of at most this many estimated tokens")
+	cmd.Flags().IntVar(&opts.maxBatches, "max-batches", 0, "only emit this many batches; remaining files keep their status")
+	cmd.Flags().BoolVar(&opts.diff, "diff", false, "send the previous skeleton plus a source diff for files with a recorded snapshot")
 
 	return cmd
 }
 
-func runGenerate(opts generateOptions) error {
+// addDeprecatedLocalFlag keeps --local accepted by scripts written before local extraction became the default.
+func addDeprecatedLocalFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("local", true, "generate skeletons locally where an extractor is registered")
+	_ = cmd.Flags().MarkDeprecated("local", "local extraction is now the default; use --no-local to prompt for every file")
+}
+
+func runGenerate(ctx context.Context, opts generateOptions) error {
+	_, err := generateSkeletons(ctx, opts)
+	return err
+}
+
+func generateSkeletons(ctx context.Context, opts generateOptions) (generateResult, error) {
+	var result generateResult
+
 	wd, err := os.Getwd()
 	if err != nil {
-		return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("determine working directory: %w", err)}
+		return result, &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("determine working directory: %w", err)}
 	}
 
 	ctxDir := filepath.Join(wd, ctxDirName)
 	if !fs.Exists(ctxDir) {
-		return &types.Error{Code: types.ExitCodeUserError, Err: fmt.Errorf("not initialized. Run 'ctx init' first")}
+		return result, &types.Error{Code: types.ExitCodeUserError, Err: fmt.Errorf("not initialized. Run 'ctx init' first")}
 	}
 
 	indexPath := filepath.Join(ctxDir, indexFileName)
 	if !fs.Exists(indexPath) {
-		return &types.Error{Code: types.ExitCodeData, Err: fmt.Errorf("missing index.json. Run 'ctx sync' to restore")}
+		return result, &types.Error{Code: types.ExitCodeData, Err: fmt.Errorf("missing index.json. Run 'ctx sync' to restore")}
 	}
 	idx, err := index.LoadIndex(indexPath)
 	if err != nil {
-		return &types.Error{Code: types.ExitCodeData, Err: err}
+		return result, &types.Error{Code: types.ExitCodeData, Err: err}
 	}
 
 	statuses, err := parseStatusFilter(opts.filter)
 	if err != nil {
-		return &types.Error{Code: types.ExitCodeUserError, Err: err}
+		return result, &types.Error{Code: types.ExitCodeUserError, Err: err}
 	}
 
 	fileFilter, err := parseFilesFilter(opts.files)
 	if err != nil {
-		return &types.Error{Code: types.ExitCodeUserError, Err: err}
+		return result, &types.Error{Code: types.ExitCodeUserError, Err: err}
 	}
 
 	var selected []string
@@ -83,10 +126,10 @@ func runGenerate(opts generateOptions) error {
 		for file := range fileFilter {
 			entry, exists := idx.Files[file]
 			if !exists {
-				return &types.Error{Code: types.ExitCodeUserError, Err: fmt.Errorf("file not tracked in index: %s", file)}
+				return result, &types.Error{Code: types.ExitCodeUserError, Err: fmt.Errorf("file not tracked in index: %s", file)}
 			}
 			if !statuses[entry.Status] {
-				return &types.Error{Code: types.ExitCodeUserError, Err: fmt.Errorf("file %s does not match filter statuses", file)}
+				return result, &types.Error{Code: types.ExitCodeUserError, Err: fmt.Errorf("file %s does not match filter statuses", file)}
 			}
 			selected = append(selected, file)
 		}
@@ -101,21 +144,85 @@ func runGenerate(opts generateOptions) error {
 	}
 
 	if len(selected) == 0 {
-		return &types.Error{Code: types.ExitCodeUserError, Err: fmt.Errorf("no files match the requested filters")}
+		return result, &types.Error{Code: types.ExitCodeUserError, Err: fmt.Errorf("no files match the requested filters")}
 	}
 
-	promptTemplate, err := skeleton.LoadPromptTemplate(idx.Config)
+	cfg, err := config.LoadConfig(filepath.Join(ctxDir, configFileName))
 	if err != nil {
-		return &types.Error{Code: types.ExitCodeData, Err: err}
+		return result, &types.Error{Code: types.ExitCodeData, Err: err}
 	}
+	registry := extractorRegistry(*cfg)
+	root := sourceRoot(wd, *cfg)
 
-	output, err := buildPromptOutput(selected, idx, promptTemplate, wd)
-	if err != nil {
-		return err
+	if opts.dryRun {
+		printGeneratePlan(selected, idx, registry, cfg, opts)
+		return result, nil
 	}
 
-	for _, path := range selected {
-		entry := idx.Files[path]
+	promptPaths := selected
+	if !opts.noLocal {
+		promptPaths, result.localFiles, err = extractLocalSkeletons(selected, idx, registry, wd, root, cfg.SkeletonPromptVersion)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	var (
+		templates *skeleton.PromptTemplates
+		sources   map[string]promptSource
+	)
+	if len(promptPaths) > 0 {
+		templates, err = skeleton.LoadPromptTemplates(*cfg)
+		if err != nil {
+			return result, &types.Error{Code: types.ExitCodeData, Err: err}
+		}
+		warnUnparsedTemplates(templates)
+
+		sources, err = readPromptSources(promptPaths, idx, wd, root, opts.diff)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if opts.send {
+		var sendErr error
+		if len(promptPaths) > 0 {
+			result.sentFiles, sendErr = sendPrompts(ctx, promptPaths, idx, cfg, templates, sources, wd, root, indexPath, opts.concurrency)
+		}
+
+		idx.Stats = index.CalculateStats(idx)
+		if err := index.SaveIndex(idx, indexPath); err != nil {
+			return result, &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+		}
+		if len(result.localFiles) > 0 {
+			fmt.Println(display.Success("Generated %d skeleton(s) locally", len(result.localFiles)))
+		}
+		return result, sendErr
+	}
+
+	var batches []promptBatch
+	if len(promptPaths) > 0 {
+		batches, err = buildPromptBatches(promptPaths, idx, templates, sources, opts.maxTokens)
+		if err != nil {
+			return result, err
+		}
+	}
+	numbered := len(batches) > 1
+	if opts.maxBatches > 0 && len(batches) > opts.maxBatches {
+		for _, batch := range batches[opts.maxBatches:] {
+			result.deferredFiles = append(result.deferredFiles, batch.paths...)
+		}
+		batches = batches[:opts.maxBatches]
+	}
+
+	promptPaths = nil
+	for _, batch := range batches {
+		promptPaths = append(promptPaths, batch.paths...)
+	}
+	result.promptFiles = promptPaths
+
+	for _, path := range promptPaths {
+		entry := stampTemplate(idx.Files[path], templates, cfg.SkeletonPromptVersion)
 		entry.Status = types.StatusPendingGeneration
 		entry.LastModified = entry.LastModified.UTC()
 		if entry.SkeletonPath == "" {
@@ -128,7 +235,15 @@ func runGenerate(opts generateOptions) error {
 	idx.Stats = index.CalculateStats(idx)
 
 	if err := index.SaveIndex(idx, indexPath); err != nil {
-		return &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+		return result, &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+	}
+
+	if len(result.localFiles) > 0 {
+		fmt.Println(display.Success("Generated %d skeleton(s) locally", len(result.localFiles)))
+	}
+
+	if len(promptPaths) == 0 {
+		return result, nil
 	}
 
 	outputPath := opts.output
@@ -136,95 +251,175 @@ func runGenerate(opts generateOptions) error {
 		outputPath = filepath.Join(ctxDir, "prompt.md")
 	}
 
-	if err := fs.WriteFile(outputPath, []byte(output)); err != nil {
-		return &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+	if err := removeBatchOutputs(outputPath); err != nil {
+		return result, &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+	}
+	for i, batch := range batches {
+		target := outputPath
+		if numbered {
+			target = batchOutputPath(outputPath, i+1)
+		}
+		if err := fs.WriteFile(target, []byte(batch.output)); err != nil {
+			return result, &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+		}
+		result.outputPaths = append(result.outputPaths, target)
 	}
 
-	fmt.Println(display.Success("Generated prompts for %d file(s)", len(selected)))
-	fmt.Println(display.Info("Prompt saved to %s", outputPath))
+	fmt.Println(display.Success("Generated prompts for %d file(s)", len(promptPaths)))
+	if !numbered {
+		fmt.Println(display.Info("Prompt saved to %s", result.outputPaths[0]))
+	} else {
+		fmt.Println(display.Info("Prompt split into %d batches:", len(batches)))
+		for i, target := range result.outputPaths {
+			fmt.Printf("  - %s (%d file(s))\n", target, len(batches[i].paths))
+		}
+	}
+	if len(result.deferredFiles) > 0 {
+		fmt.Println(display.Warning("%d file(s) left for later batches; rerun after applying these", len(result.deferredFiles)))
+	}
 	fmt.Println("Next steps:")
 	fmt.Println("  1. Paste the prompt into your AI assistant")
-	fmt.Println("  2. Create/update skeleton files at the specified paths")
-	fmt.Println("  3. Update index entries with new skeleton hashes")
+	fmt.Println("  2. Save the response and run 'ctx apply <response-file>'")
+	fmt.Println("  3. Run 'ctx status' to confirm skeletons are current")
 
 	if opts.quiet {
-		return nil
+		return result, nil
 	}
 
-	fmt.Print(output)
+	for _, batch := range batches {
+		fmt.Print(batch.output)
+	}
 
-	return nil
+	return result, nil
 }
 
-func buildPromptOutput(paths []string, idx *types.Index, promptTemplate, cwd string) (string, error) {
-	var builder strings.Builder
-
-	builder.WriteString("# Code Context Skeleton Generation\n\n")
-	builder.WriteString("You are generating structural skeletons for this codebase. Follow these instructions carefully.\n\n")
-
-	builder.WriteString("## Instructions\n\n")
-	builder.WriteString("1. For each file below, generate a skeleton using the provided template.\n")
-	builder.WriteString("2. Create or update skeleton files at the specified paths.\n")
-	builder.WriteString("3. Update `.ctx/index.json` with the new skeleton hash and status.\n\n")
+// promptSource holds the inputs for a file section: the source, the existing skeleton, and an optional diff.
+type promptSource struct {
+	content          []byte
+	previousSkeleton string
+	diff             string
+}
 
-	builder.WriteString("## Skeleton Generation Template\n\n")
-	builder.WriteString("```text\n")
-	builder.WriteString(promptTemplate)
-	if !strings.HasSuffix(promptTemplate, "\n") {
-		builder.WriteString("\n")
+func buildPromptOutput(paths []string, idx *types.Index, promptTemplate, cwd, root string) (string, error) {
+	sources, err := readPromptSources(paths, idx, cwd, root, false)
+	if err != nil {
+		return "", err
 	}
-	builder.WriteString("```\n\n")
-
-	builder.WriteString("## Files to Process\n\n")
+	return renderPrompt(paths, idx, skeleton.NewPromptTemplates(promptTemplate, nil), sources)
+}
 
-	for i, path := range paths {
-		entry := idx.Files[path]
-		sourcePath := filepath.Join(cwd, filepath.FromSlash(path))
+// readPromptSources loads source content from root and any existing skeleton from cwd for each path. With withDiff,
+// files whose skeleton and source snapshot are intact also carry a unified diff of the source.
+func readPromptSources(paths []string, idx *types.Index, cwd, root string, withDiff bool) (map[string]promptSource, error) {
+	sources := make(map[string]promptSource, len(paths))
+	for _, path := range paths {
+		sourcePath := filepath.Join(root, filepath.FromSlash(path))
 		content, err := os.ReadFile(sourcePath)
 		if err != nil {
-			return "", &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("read %s: %w", path, err)}
+			return nil, &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("read %s: %w", path, err)}
 		}
 
-		entryType := entry.Type
-		if entryType == "" {
-			entryType = scanner.DetectFileType(path)
+		entry := idx.Files[path]
+		source := promptSource{content: content, previousSkeleton: readPreviousSkeleton(cwd, entry)}
+		if withDiff && source.previousSkeleton != "" {
+			source.diff = sourceDiff(cwd, path, entry, content)
 		}
+		sources[path] = source
+	}
+	return sources, nil
+}
 
-		builder.WriteString(fmt.Sprintf("### File %d: %s\n", i+1, path))
-		builder.WriteString(fmt.Sprintf("**Status:** %s\n", entry.Status))
-		if entryType != "" {
-			builder.WriteString(fmt.Sprintf("**Type:** %s\n", entryType))
-		}
-		builder.WriteString(fmt.Sprintf("**Skeleton Path:** %s\n\n", entry.SkeletonPath))
-
-		builder.WriteString("**Source Code:**\n")
-		lang := languageFromExtension(path)
-		builder.WriteString("```")
-		builder.WriteString(lang)
-		builder.WriteString("\n")
-		builder.Write(content)
-		if len(content) == 0 || content[len(content)-1] != '\n' {
-			builder.WriteString("\n")
+func readPreviousSkeleton(cwd string, entry types.FileEntry) string {
+	if entry.SkeletonPath == "" {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(cwd, filepath.FromSlash(entry.SkeletonPath)))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func sourceDiff(cwd, path string, entry types.FileEntry, content []byte) string {
+	snapshot, ok := previousSourceSnapshot(cwd, entry)
+	if !ok {
+		return ""
+	}
+	return diff.Unified("a/"+path, "b/"+path, string(snapshot), string(content), diff.DefaultContext)
+}
+
+// warnUnparsedTemplates notes each skeleton template that is sent verbatim because it is not a valid template.
+func warnUnparsedTemplates(templates *skeleton.PromptTemplates) {
+	unparsed := templates.Unparsed()
+	names := make([]string, 0, len(unparsed))
+	for name := range unparsed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Println(display.Warning("Using the %s template verbatim: %v", name, unparsed[name]))
+	}
+}
+
+// renderPrompt renders the scaffold for paths. Files are ordered by template, and consecutive files whose
+// rendered skeleton template is identical share one template section.
+func renderPrompt(paths []string, idx *types.Index, templates *skeleton.PromptTemplates, sources map[string]promptSource) (string, error) {
+	data := skeleton.PromptData{PathMarker: skeleton.PathMarker, Config: idx.Config}
+	for i, path := range orderByTemplate(paths, idx, templates) {
+		file := promptFileData(i+1, path, idx, sources[path])
+		name, text := templates.Lookup(path, file.Type)
+		rendered, err := skeleton.RenderTemplate(name, text, file)
+		if err != nil {
+			return "", &types.Error{Code: types.ExitCodeData, Err: err}
 		}
-		builder.WriteString("```\n\n")
 
-		if i < len(paths)-1 {
-			builder.WriteString("---\n\n")
+		if n := len(data.Groups); n > 0 && data.Groups[n-1].Name == name && data.Groups[n-1].Template == rendered {
+			data.Groups[n-1].Files = append(data.Groups[n-1].Files, file)
+			continue
 		}
+		data.Groups = append(data.Groups, skeleton.PromptGroup{Name: name, Template: rendered, Files: []skeleton.FileData{file}})
 	}
 
-	builder.WriteString("## Index Updates Required\n\n")
-	builder.WriteString("After generating skeletons, update `.ctx/index.json`:\n\n")
-	builder.WriteString("1. Write the skeleton file to the specified path.\n")
-	builder.WriteString("2. Calculate the SHA-256 hash of the skeleton content.\n")
-	builder.WriteString("3. Update the file entry with `status: \"current\"`, the new `skeletonHash`, and the current timestamp for `lastModified`.\n")
-	builder.WriteString("4. Recalculate the index stats to reflect the changes.\n\n")
-	builder.WriteString("**Index Path:** .ctx/index.json\n\n")
+	output, err := skeleton.RenderScaffold(templates.Scaffold, data)
+	if err != nil {
+		return "", &types.Error{Code: types.ExitCodeData, Err: err}
+	}
+	return output, nil
+}
+
+// orderByTemplate orders paths so files sharing a template are adjacent, keeping first-appearance order.
+func orderByTemplate(paths []string, idx *types.Index, templates *skeleton.PromptTemplates) []string {
+	var names []string
+	grouped := make(map[string][]string)
+	for _, path := range paths {
+		name, _ := templates.Lookup(path, fileTypeFor(path, idx.Files[path]))
+		if _, ok := grouped[name]; !ok {
+			names = append(names, name)
+		}
+		grouped[name] = append(grouped[name], path)
+	}
 
-	builder.WriteString("## Verification\n\n")
-	builder.WriteString("After completion, run `ctx status` to verify that all files are marked current.\n")
+	ordered := make([]string, 0, len(paths))
+	for _, name := range names {
+		ordered = append(ordered, grouped[name]...)
+	}
+	return ordered
+}
 
-	return builder.String(), nil
+func promptFileData(number int, path string, idx *types.Index, source promptSource) skeleton.FileData {
+	entry := idx.Files[path]
+	return skeleton.FileData{
+		Number:           number,
+		Path:             path,
+		Type:             fileTypeFor(path, entry),
+		Status:           string(entry.Status),
+		Language:         languageFromExtension(path),
+		SkeletonPath:     entry.SkeletonPath,
+		PreviousSkeleton: source.previousSkeleton,
+		Source:           string(source.content),
+		Diff:             source.diff,
+		Config:           idx.Config,
+	}
 }
 
 func parseStatusFilter(raw string) (map[types.Status]bool, error) {
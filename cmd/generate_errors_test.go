@@ -1,16 +1,23 @@
 package cmd
 
 import (
+	"context"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/dakshpareek/ctx/internal/config"
+	"github.com/dakshpareek/ctx/internal/fs"
+	"github.com/dakshpareek/ctx/internal/hash"
+	"github.com/dakshpareek/ctx/internal/types"
 )
 
 func TestRunGenerateErrors(t *testing.T) {
 	dir := t.TempDir()
 	cleanup := changeDir(t, dir)
-	err := runGenerate(generateOptions{})
+	err := runGenerate(context.Background(), generateOptions{noLocal: true})
 	if err == nil {
 		t.Fatalf("expected error when not initialized")
 	}
@@ -21,7 +28,7 @@ func TestRunGenerateErrors(t *testing.T) {
 	cleanup = changeDir(t, dir)
 	defer cleanup()
 
-	if err := runGenerate(generateOptions{files: "unknown.go"}); err == nil {
+	if err := runGenerate(context.Background(), generateOptions{files: "unknown.go", noLocal: true}); err == nil {
 		t.Fatalf("expected error for untracked file")
 	}
 }
@@ -35,7 +42,7 @@ func TestRunGenerateStdout(t *testing.T) {
 	defer cleanup()
 
 	output := captureOutput(t, func() {
-		if err := runGenerate(generateOptions{}); err != nil {
+		if err := runGenerate(context.Background(), generateOptions{noLocal: true}); err != nil {
 			t.Fatalf("runGenerate: %v", err)
 		}
 	})
@@ -49,8 +56,8 @@ func TestGenerateCommandDefaultFilterIncludesPending(t *testing.T) {
 	writeTempFile(t, dir, "main.go", "package main\n")
 	_, _ = executeCommand(t, dir, "init")
 
-	_, _ = executeCommand(t, dir, "generate")
-	_, _ = executeCommand(t, dir, "generate")
+	_, _ = executeCommand(t, dir, "generate", "--no-local")
+	_, _ = executeCommand(t, dir, "generate", "--no-local")
 }
 
 func TestRunGenerateWritesDefaultPromptFile(t *testing.T) {
@@ -61,7 +68,7 @@ func TestRunGenerateWritesDefaultPromptFile(t *testing.T) {
 	cleanup := changeDir(t, dir)
 	defer cleanup()
 
-	if err := runGenerate(generateOptions{quiet: true}); err != nil {
+	if err := runGenerate(context.Background(), generateOptions{quiet: true, noLocal: true}); err != nil {
 		t.Fatalf("runGenerate: %v", err)
 	}
 
@@ -84,7 +91,7 @@ func TestRunGenerateQuietSuppressesPromptPrint(t *testing.T) {
 	defer cleanup()
 
 	output := captureOutput(t, func() {
-		if err := runGenerate(generateOptions{quiet: true}); err != nil {
+		if err := runGenerate(context.Background(), generateOptions{quiet: true, noLocal: true}); err != nil {
 			t.Fatalf("runGenerate: %v", err)
 		}
 	})
@@ -96,3 +103,122 @@ func TestRunGenerateQuietSuppressesPromptPrint(t *testing.T) {
 		t.Fatalf("expected summary output even in quiet mode")
 	}
 }
+
+func TestGenerateLocalWritesGoSkeletons(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "main.go", "package main\n\n// Run starts the app.\nfunc Run() error { return nil }\n")
+	writeTempFile(t, dir, "web/app.ts", "export const app = 1;\n")
+	_, _ = executeCommand(t, dir, "init")
+
+	_ = execAndCaptureStdout(t, dir, "generate", "--quiet")
+
+	idx := loadIndex(t, dir)
+	goEntry := idx.Files["main.go"]
+	if goEntry.Status != types.StatusCurrent {
+		t.Fatalf("expected main.go current after local generation, got %s", goEntry.Status)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, goEntry.SkeletonPath))
+	if err != nil {
+		t.Fatalf("read skeleton: %v", err)
+	}
+	if !strings.Contains(string(data), "`func Run() error`") {
+		t.Fatalf("expected extracted signature in skeleton:\n%s", data)
+	}
+	if goEntry.SkeletonHash != hash.HashContent(data) {
+		t.Fatalf("expected skeleton hash to match written content")
+	}
+
+	if status := idx.Files["web/app.ts"].Status; status != types.StatusPendingGeneration {
+		t.Fatalf("expected app.ts pending generation, got %s", status)
+	}
+	prompt, err := os.ReadFile(filepath.Join(dir, ".ctx", "prompt.md"))
+	if err != nil {
+		t.Fatalf("read prompt: %v", err)
+	}
+	if strings.Contains(string(prompt), "main.go") {
+		t.Fatalf("expected locally generated file to be left out of the prompt")
+	}
+}
+
+func TestGenerateLocalUsesConfiguredExtractors(t *testing.T) {
+	if _, err := exec.LookPath("tr"); err != nil {
+		t.Skip("tr not available")
+	}
+
+	dir := t.TempDir()
+	writeTempFile(t, dir, "web/app.ts", "export const app = 1;\n")
+	_, _ = executeCommand(t, dir, "init")
+
+	configPath := filepath.Join(dir, ".ctx", "config.json")
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	cfg.Extractors = map[string]string{".ts": "tr a-z A-Z"}
+	if err := fs.WriteJSON(configPath, cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	stdout := execAndCaptureStdout(t, dir, "ask", "--quiet")
+	if !strings.Contains(stdout, "generated locally") {
+		t.Fatalf("expected local generation summary:\n%s", stdout)
+	}
+
+	idx := loadIndex(t, dir)
+	entry := idx.Files["web/app.ts"]
+	if entry.Status != types.StatusCurrent {
+		t.Fatalf("expected app.ts current, got %s", entry.Status)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, entry.SkeletonPath))
+	if err != nil {
+		t.Fatalf("read skeleton: %v", err)
+	}
+	if !strings.Contains(string(data), "EXPORT CONST APP") {
+		t.Fatalf("expected command extractor output, got %q", data)
+	}
+}
+
+func TestGenerateDiffSendsPreviousSkeletonForStaleFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "main.go", "package main\n\nfunc Run() error { return nil }\n")
+	writeTempFile(t, dir, "web/app.ts", "export const app = 1;\n")
+	_, _ = executeCommand(t, dir, "init")
+	_ = execAndCaptureStdout(t, dir, "generate", "--quiet")
+
+	snapshot := filepath.Join(dir, ".ctx", "snapshots", "main.go")
+	if _, err := os.Stat(snapshot); err != nil {
+		t.Fatalf("expected source snapshot after generation: %v", err)
+	}
+
+	writeTempFile(t, dir, "main.go", "package main\n\nfunc Run() error { return nil }\n\nfunc Stop() {}\n")
+	_, _ = executeCommand(t, dir, "sync")
+	_ = execAndCaptureStdout(t, dir, "generate", "--no-local", "--diff", "--quiet")
+
+	prompt, err := os.ReadFile(filepath.Join(dir, ".ctx", "prompt.md"))
+	if err != nil {
+		t.Fatalf("read prompt: %v", err)
+	}
+	text := string(prompt)
+	for _, want := range []string{"**Previous Skeleton:**", "```diff", "+func Stop() {}", "`func Run() error`"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in prompt:\n%s", want, text)
+		}
+	}
+	if !strings.Contains(text, "export const app = 1;") {
+		t.Fatalf("expected full source for file without a snapshot:\n%s", text)
+	}
+}
+
+func TestGenerateExtractsLocallyByDefault(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "main.go", "package main\n\nfunc Run() {}\n")
+	_, _ = executeCommand(t, dir, "init")
+
+	stdout := execAndCaptureStdout(t, dir, "generate", "--quiet")
+	if !strings.Contains(stdout, "Generated 1 skeleton(s) locally") {
+		t.Fatalf("expected local extraction without flags, got:\n%s", stdout)
+	}
+	if entry := loadIndex(t, dir).Files["main.go"]; entry.Status != types.StatusCurrent {
+		t.Fatalf("expected main.go current, got %s", entry.Status)
+	}
+}
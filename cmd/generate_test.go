@@ -9,6 +9,7 @@ import (
 
 	"os"
 
+	"github.com/dakshpareek/ctx/internal/skeleton"
 	"github.com/dakshpareek/ctx/internal/types"
 )
 
@@ -103,7 +104,7 @@ func TestBuildPromptOutput(t *testing.T) {
 		},
 	}
 
-	output, err := buildPromptOutput([]string{"src/example.go"}, idx, "template", tempDir)
+	output, err := buildPromptOutput([]string{"src/example.go"}, idx, "template", tempDir, tempDir)
 	if err != nil {
 		t.Fatalf("buildPromptOutput error: %v", err)
 	}
@@ -153,3 +154,77 @@ func TestBuildJSONExport(t *testing.T) {
 		t.Fatalf("expected count 1, got %v", parsed["count"])
 	}
 }
+
+func TestRenderPromptGroupsFilesByTemplate(t *testing.T) {
+	idx := &types.Index{
+		Files: map[string]types.FileEntry{
+			"api/user.controller.ts": {Path: "api/user.controller.ts", Type: "controller"},
+			"api/user.dto.ts":        {Path: "api/user.dto.ts", Type: "dto"},
+			"api/order.dto.ts":       {Path: "api/order.dto.ts", Type: "dto"},
+		},
+	}
+	templates := skeleton.NewPromptTemplates("default prompt", map[string]string{"dto": "dto prompt"})
+	paths := []string{"api/user.dto.ts", "api/user.controller.ts", "api/order.dto.ts"}
+
+	output, err := renderPrompt(paths, idx, templates, map[string]promptSource{})
+	if err != nil {
+		t.Fatalf("renderPrompt error: %v", err)
+	}
+
+	dtoSection := strings.Index(output, "## Skeleton Generation Template (dto)")
+	defaultSection := strings.Index(output, "## Skeleton Generation Template\n")
+	if dtoSection < 0 || defaultSection < 0 || dtoSection > defaultSection {
+		t.Fatalf("expected dto template section before default section:\n%s", output)
+	}
+	for i, heading := range []string{"### File 1: api/user.dto.ts", "### File 2: api/order.dto.ts", "### File 3: api/user.controller.ts"} {
+		pos := strings.Index(output, heading)
+		if pos < 0 {
+			t.Fatalf("expected %q in output:\n%s", heading, output)
+		}
+		if i < 2 && pos > defaultSection || i == 2 && pos < defaultSection {
+			t.Fatalf("expected %q grouped under its template:\n%s", heading, output)
+		}
+	}
+}
+
+func TestRenderPromptExecutesTemplates(t *testing.T) {
+	idx := &types.Index{
+		Config: types.Config{SkeletonPromptVersion: "9.9"},
+		Files: map[string]types.FileEntry{
+			"api/user.dto.ts":  {Path: "api/user.dto.ts", Type: "dto", Status: types.StatusMissing},
+			"api/order.dto.ts": {Path: "api/order.dto.ts", Type: "dto", Status: types.StatusStale},
+		},
+	}
+	templates := skeleton.NewPromptTemplates("default", map[string]string{"dto": "Summarize {{.Path}} as a {{.Type}}."})
+	templates.Scaffold = "v{{.Config.SkeletonPromptVersion}}\n{{range .Groups}}[{{.Template}}]{{range .Files}} {{.Number}}:{{.Status}}{{end}}\n{{end}}"
+
+	output, err := renderPrompt([]string{"api/user.dto.ts", "api/order.dto.ts"}, idx, templates, map[string]promptSource{})
+	if err != nil {
+		t.Fatalf("renderPrompt error: %v", err)
+	}
+
+	want := "v9.9\n[Summarize api/user.dto.ts as a dto.] 1:missing\n[Summarize api/order.dto.ts as a dto.] 2:stale\n"
+	if output != want {
+		t.Fatalf("renderPrompt = %q, expected %q", output, want)
+	}
+
+	templates.Scaffold = "{{.Unknown}}"
+	if _, err := renderPrompt([]string{"api/user.dto.ts"}, idx, templates, map[string]promptSource{}); err == nil {
+		t.Fatalf("expected error for invalid scaffold")
+	}
+}
+
+func TestGenerateWithLiteralBraceTemplate(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "main.go", "package main\n")
+	_, _ = executeCommand(t, dir, "init")
+	writeTempFile(t, dir, filepath.Join(".ctx", skeleton.PromptFileName), "Describe props such as style={{color: 'red'}}.\n")
+
+	stdout := execAndCaptureStdout(t, dir, "generate", "--no-local")
+	if !strings.Contains(stdout, "Using the default template verbatim") {
+		t.Fatalf("expected a verbatim template warning, got:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, "style={{color: 'red'}}") {
+		t.Fatalf("expected the template text in the prompt, got:\n%s", stdout)
+	}
+}
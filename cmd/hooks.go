@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dakshpareek/ctx/internal/display"
+	"github.com/dakshpareek/ctx/internal/git"
+	"github.com/dakshpareek/ctx/internal/hooks"
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+// syncHooks run a quiet 'ctx sync' after git changes the working tree.
+var syncHooks = []string{"post-commit", "post-merge", "post-checkout"}
+
+const preCommitHook = "pre-commit"
+
+type hooksInstallOptions struct {
+	preCommit bool
+}
+
+func newHooksCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hooks",
+		Short: "Install git hooks that keep the index fresh",
+		Long: `Install or remove git hooks that run 'ctx sync' after commits, merges and checkouts,
+and optionally 'ctx validate --strict --staged' before each commit.
+
+Existing hooks keep working: ctx moves them aside and runs them first. core.hooksPath is honored.`,
+	}
+
+	cmd.AddCommand(newHooksInstallCmd(), newHooksUninstallCmd())
+	return cmd
+}
+
+func newHooksInstallCmd() *cobra.Command {
+	opts := hooksInstallOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install post-commit, post-merge and post-checkout hooks",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHooksInstall(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.preCommit, "pre-commit", false, "also install a pre-commit hook that runs 'ctx validate --strict --staged'")
+
+	return cmd
+}
+
+func newHooksUninstallCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove ctx hooks and restore the hooks they chained to",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHooksUninstall()
+		},
+	}
+}
+
+func runHooksInstall(opts hooksInstallOptions) error {
+	hooksDir, workspace, err := locateHooks()
+	if err != nil {
+		return err
+	}
+
+	names := syncHooks
+	if opts.preCommit {
+		names = append(append([]string{}, syncHooks...), preCommitHook)
+	}
+
+	for _, name := range names {
+		chained, err := hooks.Install(hooksDir, name, hookBody(name, workspace))
+		if err != nil {
+			return &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+		}
+		if chained {
+			fmt.Println(display.Success("Installed %s (runs after the existing hook, kept as %s%s)", name, name, hooks.ChainedSuffix))
+		} else {
+			fmt.Println(display.Success("Installed %s", name))
+		}
+	}
+	fmt.Println(display.Info("Hooks directory: %s", hooksDir))
+	return nil
+}
+
+func runHooksUninstall() error {
+	hooksDir, _, err := locateHooks()
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	for _, name := range append(append([]string{}, syncHooks...), preCommitHook) {
+		ok, err := hooks.Uninstall(hooksDir, name)
+		if err != nil {
+			return &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+		}
+		if ok {
+			removed++
+			fmt.Println(display.Success("Removed %s", name))
+		}
+	}
+	if removed == 0 {
+		fmt.Println(display.Info("No ctx hooks installed in %s", hooksDir))
+	}
+	return nil
+}
+
+// locateHooks returns the git hooks directory and the workspace path relative to the top of the working tree,
+// which is where git runs hooks from.
+func locateHooks() (string, string, error) {
+	ctxDir, _, err := ensureWorkspace(false)
+	if err != nil {
+		return "", "", err
+	}
+
+	if !git.IsGitRepo() {
+		return "", "", &types.Error{Code: types.ExitCodeUserError, Err: fmt.Errorf("not a git repository")}
+	}
+	hooksDir, err := git.HooksDir()
+	if err != nil {
+		return "", "", &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+	}
+	top, err := git.TopLevel()
+	if err != nil {
+		return "", "", &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+	}
+
+	workspace, err := filepath.Rel(top, filepath.Dir(ctxDir))
+	if err != nil || workspace == ".." || strings.HasPrefix(workspace, ".."+string(filepath.Separator)) {
+		return "", "", &types.Error{Code: types.ExitCodeUserError, Err: fmt.Errorf("workspace is outside the git working tree %s", top)}
+	}
+	return hooksDir, filepath.ToSlash(workspace), nil
+}
+
+// hookBody is the ctx part of a hook script. Hooks do nothing when ctx is not on PATH, so teammates without it
+// can still commit; the post-* hooks never fail the git command that triggered them.
+func hookBody(name, workspace string) string {
+	var lines []string
+	if name == "post-checkout" {
+		// The third argument is 1 for branch checkouts and 0 for checking out individual files.
+		lines = append(lines, `[ "$3" = "1" ] || exit 0`)
+	}
+	lines = append(lines, "command -v ctx >/dev/null 2>&1 || exit 0")
+
+	if name == preCommitHook {
+		if workspace != "." {
+			lines = append(lines, "cd "+hooks.Quote(workspace)+" || exit 1")
+		}
+		lines = append(lines, "exec ctx validate --strict --staged")
+		return strings.Join(lines, "\n")
+	}
+
+	if workspace != "." {
+		lines = append(lines, "cd "+hooks.Quote(workspace)+" || exit 0")
+	}
+	lines = append(lines, "ctx sync >/dev/null 2>&1 || true")
+	return strings.Join(lines, "\n")
+}
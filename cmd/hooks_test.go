@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dakshpareek/ctx/internal/hooks"
+)
+
+func TestHooksInstallChainsAndUninstallRestores(t *testing.T) {
+	dir := initGitRepo(t)
+	writeTempFile(t, dir, "app/main.go", "package main\n")
+	commitAll(t, dir, "init")
+	if err := runGitCommand(dir, "config", "core.hooksPath", ".githooks"); err != nil {
+		t.Skipf("git config failed: %v", err)
+	}
+	existing := "#!/bin/sh\necho lint\n"
+	writeTempFile(t, dir, ".githooks/post-commit", existing)
+
+	workspace := filepath.Join(dir, "app")
+	_, _ = executeCommand(t, workspace, "init")
+
+	stdout := execAndCaptureStdout(t, workspace, "hooks", "install", "--pre-commit")
+	if !strings.Contains(stdout, "Installed post-commit (runs after the existing hook") {
+		t.Fatalf("expected existing hook chained:\n%s", stdout)
+	}
+
+	hooksDir := filepath.Join(dir, ".githooks")
+	for _, name := range []string{"post-commit", "post-merge", "post-checkout", "pre-commit"} {
+		data, err := os.ReadFile(filepath.Join(hooksDir, name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		if !strings.Contains(string(data), hooks.Marker) || !strings.Contains(string(data), "cd 'app'") {
+			t.Fatalf("expected %s to run ctx from the workspace:\n%s", name, data)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(hooksDir, "pre-commit")); !strings.Contains(string(data), "ctx validate --strict --staged") {
+		t.Fatalf("expected pre-commit to validate:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git", "hooks", "post-commit")); !os.IsNotExist(err) {
+		t.Fatalf("expected core.hooksPath to be used instead of .git/hooks, got %v", err)
+	}
+
+	_ = execAndCaptureStdout(t, workspace, "hooks", "uninstall")
+	data, err := os.ReadFile(filepath.Join(hooksDir, "post-commit"))
+	if err != nil || string(data) != existing {
+		t.Fatalf("expected the original post-commit restored, got %q (%v)", data, err)
+	}
+	for _, name := range []string{"post-merge", "post-checkout", "pre-commit"} {
+		if _, err := os.Stat(filepath.Join(hooksDir, name)); !os.IsNotExist(err) {
+			t.Fatalf("expected %s removed, got %v", name, err)
+		}
+	}
+}
@@ -11,7 +11,6 @@ import (
 	"github.com/dakshpareek/ctx/internal/config"
 	"github.com/dakshpareek/ctx/internal/display"
 	"github.com/dakshpareek/ctx/internal/fs"
-	"github.com/dakshpareek/ctx/internal/hash"
 	"github.com/dakshpareek/ctx/internal/index"
 	"github.com/dakshpareek/ctx/internal/scanner"
 	"github.com/dakshpareek/ctx/internal/skeleton"
@@ -26,21 +25,29 @@ const (
 	skeletonPromptName = skeleton.PromptFileName
 )
 
+type initOptions struct {
+	jobs int
+}
+
 func newInitCmd() *cobra.Command {
+	opts := initOptions{}
+
 	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "Initialize the .ctx/ workspace",
 		Long: `ctx init bootstraps the ctx workspace by creating .ctx/,
 writing default configuration, and preparing an index for all tracked files.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runInit()
+			return runInit(opts)
 		},
 	}
 
+	cmd.Flags().IntVar(&opts.jobs, "jobs", 0, "number of files to scan and hash in parallel (default: number of CPUs)")
+
 	return cmd
 }
 
-func runInit() error {
+func runInit(opts initOptions) error {
 	wd, err := os.Getwd()
 	if err != nil {
 		return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("determine working directory: %w", err)}
@@ -76,7 +83,6 @@ func runInit() error {
 	}
 
 	cfg := config.GetDefaultConfig()
-	cfg.RootPath = "."
 	configPath := filepath.Join(ctxDir, configFileName)
 	if err := fs.WriteJSON(configPath, cfg); err != nil {
 		return &types.Error{Code: types.ExitCodeFileSystem, Err: err}
@@ -95,38 +101,53 @@ func runInit() error {
 	fmt.Println(display.Success("Updated .gitignore"))
 	fmt.Println(display.Info("Scanning codebase..."))
 
+	// Record the commit and time the scan starts from, so commits and edits made while it runs are picked up by the next sync.
+	head, started := headCommit(), time.Now().UTC()
+
+	root := sourceRoot(wd, *cfg)
 	scanCfg := *cfg
-	scanCfg.RootPath = "."
+	scanCfg.RootPath = root
+
+	cache := openHashCache(ctxDir, false)
+	defer saveHashCache(cache)
 
-	files, err := scanner.ScanFiles(scanCfg)
+	scanned, err := scanner.ScanCached(scanCfg, opts.jobs, cache)
 	if err != nil {
 		return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("scan files: %w", err)}
 	}
+	files := scanned.Files
+
+	typeRules, err := scanner.CompileTypeRules(cfg.FileTypes)
+	if err != nil {
+		return &types.Error{Code: types.ExitCodeData, Err: err}
+	}
 
 	idx := index.CreateEmptyIndex()
 	idx.Config = *cfg
+	idx.Skipped = scanned.Skipped
 
-	for _, relPath := range files {
-		fullPath := filepath.Join(wd, relPath)
-		info, err := os.Stat(fullPath)
-		if err != nil {
-			return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("stat %s: %w", relPath, err)}
-		}
+	states, err := hashFiles(root, files, cache, opts.jobs)
+	if err != nil {
+		return err
+	}
 
-		fileHash, err := hash.HashFile(fullPath)
-		if err != nil {
-			return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("hash %s: %w", relPath, err)}
+	for i, relPath := range files {
+		state := states[i]
+		if state.missing {
+			return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("stat %s: %w", relPath, os.ErrNotExist)}
 		}
+		info := state.info
 
 		entry := types.FileEntry{
 			Path:         relPath,
-			Hash:         fileHash,
+			Hash:         state.hash,
 			SkeletonHash: "",
 			SkeletonPath: skeleton.PathForSource(relPath),
 			LastModified: info.ModTime().UTC(),
 			Status:       types.StatusMissing,
-			Type:         scanner.DetectFileType(relPath),
+			Type:         typeRules.Detect(os.DirFS(root), relPath),
 			Size:         info.Size(),
+			Package:      scanned.Packages[relPath],
 		}
 
 		if idx.Files == nil {
@@ -135,7 +156,8 @@ func runInit() error {
 		idx.Files[relPath] = entry
 	}
 
-	idx.LastSync = time.Now().UTC()
+	idx.LastSyncedCommit = head
+	idx.LastSync = started
 	idx.Stats = index.CalculateStats(idx)
 
 	indexPath := filepath.Join(ctxDir, indexFileName)
@@ -144,6 +166,9 @@ func runInit() error {
 	}
 
 	fmt.Printf("  Found %d files (all marked missing)\n", len(files))
+	if len(scanned.Skipped) > 0 {
+		fmt.Printf("  Skipped %d generated, binary or oversized file(s) (see 'ctx status -v')\n", len(scanned.Skipped))
+	}
 	fmt.Println(display.Success("Index created"))
 	fmt.Println()
 	fmt.Println("Next steps:")
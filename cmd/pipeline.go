@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -20,30 +21,49 @@ func newPipelineCmd() *cobra.Command {
 		Long:  advancedDescription,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			printAdvancedNotice("ctx ask")
-			if err := runSync(syncOpts); err != nil {
-				return err
-			}
-			if err := runGenerate(genOpts); err != nil {
-				return err
-			}
-
-			if genOpts.quiet {
-				fmt.Println(display.Info("Prompt generation finished silently (--quiet). Run 'ctx update' after saving skeletons."))
-			} else {
-				fmt.Println()
-				fmt.Println(display.Info("Next steps: share the prompt with your AI assistant, update skeletons, then run 'ctx update'."))
-			}
-
-			return nil
+			return withWorkspaceLock(cmd.CommandPath(), func() error {
+				return runPipeline(cmd.Context(), syncOpts, genOpts)
+			})
 		},
 	}
 
 	cmd.Flags().BoolVar(&syncOpts.full, "full", false, "force full scan (ignore git diff)")
 	cmd.Flags().BoolVarP(&syncOpts.verbose, "verbose", "v", false, "show detailed file changes during sync")
+	cmd.Flags().IntVar(&syncOpts.jobs, "jobs", 0, "number of files to scan and hash in parallel during sync (default: number of CPUs)")
 	cmd.Flags().StringVar(&genOpts.filter, "filter", genOpts.filter, "comma-separated statuses to include")
 	cmd.Flags().StringVar(&genOpts.files, "files", "", "comma-separated list of specific files to include in the prompt")
 	cmd.Flags().StringVarP(&genOpts.output, "output", "o", "", "write prompt to a specific file")
 	cmd.Flags().BoolVarP(&genOpts.quiet, "quiet", "q", false, "Suppress prompt body (still writes to file)")
+	cmd.Flags().BoolVar(&genOpts.noLocal, "no-local", false, "prompt for every file instead of generating skeletons locally where an extractor is registered")
+	addDeprecatedLocalFlag(cmd)
+	cmd.Flags().BoolVar(&genOpts.send, "send", false, "send per-file prompts to the provider configured in config.json and save the replies")
+	cmd.Flags().BoolVar(&genOpts.dryRun, "dry-run", false, "show which files would be processed without generating anything")
+	cmd.Flags().IntVar(&genOpts.concurrency, "concurrency", 0, "maximum parallel provider requests (default from config, or 4)")
+	cmd.Flags().IntVar(&genOpts.maxTokens, "max-tokens", 0, "split the prompt into numbered files of at most this many estimated tokens")
+	cmd.Flags().IntVar(&genOpts.maxBatches, "max-batches", 0, "only emit this many batches; remaining files keep their status")
+	cmd.Flags().BoolVar(&genOpts.diff, "diff", false, "send the previous skeleton plus a source diff for files with a recorded snapshot")
 
 	return cmd
 }
+
+func runPipeline(ctx context.Context, syncOpts syncOptions, genOpts generateOptions) error {
+	if err := runSync(syncOpts); err != nil {
+		return err
+	}
+	if err := runGenerate(ctx, genOpts); err != nil {
+		return err
+	}
+
+	if genOpts.send || genOpts.dryRun {
+		return nil
+	}
+
+	if genOpts.quiet {
+		fmt.Println(display.Info("Prompt generation finished silently (--quiet). Run 'ctx apply' with the AI response."))
+	} else {
+		fmt.Println()
+		fmt.Println(display.Info("Next steps: share the prompt with your AI assistant, then run 'ctx apply' with its response."))
+	}
+
+	return nil
+}
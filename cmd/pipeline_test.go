@@ -17,7 +17,7 @@ func TestPipelineGeneratesPrompt(t *testing.T) {
 		t.Fatalf("write change: %v", err)
 	}
 
-	_, _ = executeCommand(t, tempDir, "pipeline", "--output", "pipeline.md")
+	_, _ = executeCommand(t, tempDir, "pipeline", "--no-local", "--output", "pipeline.md")
 
 	data, err := os.ReadFile(filepath.Join(tempDir, "pipeline.md"))
 	if err != nil {
@@ -37,7 +37,7 @@ func TestPipelineDefaultOutputAndQuietMode(t *testing.T) {
 		t.Fatalf("write change: %v", err)
 	}
 
-	stdout := execAndCaptureStdout(t, dir, "pipeline")
+	stdout := execAndCaptureStdout(t, dir, "pipeline", "--no-local")
 	defaultPrompt := filepath.Join(dir, ".ctx", "prompt.md")
 	if _, err := os.Stat(defaultPrompt); err != nil {
 		t.Fatalf("expected default prompt at %s: %v", defaultPrompt, err)
@@ -46,7 +46,7 @@ func TestPipelineDefaultOutputAndQuietMode(t *testing.T) {
 		t.Fatalf("expected stdout to mention default prompt path, got:\n%s", stdout)
 	}
 
-	stdout = execAndCaptureStdout(t, dir, "pipeline", "--quiet")
+	stdout = execAndCaptureStdout(t, dir, "pipeline", "--no-local", "--quiet")
 	if strings.Contains(stdout, "Code Context Skeleton Generation") {
 		t.Fatalf("quiet pipeline should suppress prompt body, got:\n%s", stdout)
 	}
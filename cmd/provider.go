@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+
+	"github.com/dakshpareek/ctx/internal/display"
+	"github.com/dakshpareek/ctx/internal/index"
+	"github.com/dakshpareek/ctx/internal/provider"
+	"github.com/dakshpareek/ctx/internal/skeleton"
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+type providerOutcome struct {
+	path    string
+	content string
+	err     error
+}
+
+// sendPrompts sends one prompt per file to the configured provider and saves each reply as a skeleton.
+// Each successful reply is written and the index persisted as soon as it arrives, so an interrupted run
+// keeps the skeletons already received. Failures are reported and left untouched.
+func sendPrompts(ctx context.Context, paths []string, idx *types.Index, cfg *types.Config, templates *skeleton.PromptTemplates, sources map[string]promptSource, cwd, root, indexPath string, concurrency int) ([]string, error) {
+	client, err := provider.NewClient(cfg.Provider)
+	if err != nil {
+		return nil, &types.Error{Code: types.ExitCodeUserError, Err: err}
+	}
+
+	prompts := make([]string, len(paths))
+	for i, path := range paths {
+		prompts[i], err = renderPrompt([]string{path}, idx, templates, sources)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if concurrency <= 0 {
+		concurrency = provider.Concurrency(cfg.Provider)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	fmt.Println(display.Info("Sending %d prompt(s) to %s (model %s, concurrency %d)...", len(paths), client.Endpoint(), client.Model(), concurrency))
+
+	outcomes := make(chan providerOutcome)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	go func() {
+		defer close(outcomes)
+		for i := range paths {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				wg.Wait()
+				return
+			}
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				reply, err := client.Complete(ctx, prompts[i])
+				outcome := providerOutcome{path: paths[i], err: err}
+				if err == nil {
+					outcome.content = skeleton.ContentFromReply(reply)
+					if outcome.content == "" {
+						outcome.err = fmt.Errorf("empty skeleton in reply")
+					}
+				}
+				outcomes <- outcome
+			}(i)
+		}
+		wg.Wait()
+	}()
+
+	var (
+		sent    []string
+		failed  int
+		saveErr error
+	)
+	for outcome := range outcomes {
+		if saveErr != nil {
+			continue
+		}
+		if outcome.err != nil {
+			failed++
+			fmt.Println(display.Error("%s: %v", outcome.path, outcome.err))
+			continue
+		}
+
+		entry := stampTemplate(idx.Files[outcome.path], templates, cfg.SkeletonPromptVersion)
+		entry, err := saveSkeleton(cwd, root, entry, outcome.content)
+		if err != nil {
+			saveErr = err
+			continue
+		}
+		idx.Files[outcome.path] = entry
+		idx.Stats = index.CalculateStats(idx)
+		if err := index.SaveIndex(idx, indexPath); err != nil {
+			saveErr = &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+			continue
+		}
+		sent = append(sent, outcome.path)
+	}
+	if saveErr != nil {
+		return sent, saveErr
+	}
+
+	fmt.Println(display.Success("Generated %d skeleton(s) via provider", len(sent)))
+	if ctx.Err() != nil {
+		return sent, &types.Error{Code: types.ExitCodeData, Err: fmt.Errorf("interrupted: %d of %d file(s) generated", len(sent), len(paths))}
+	}
+	if failed > 0 {
+		return sent, &types.Error{Code: types.ExitCodeData, Err: fmt.Errorf("%d file(s) failed to generate", failed)}
+	}
+	return sent, nil
+}
+
+// printGeneratePlan describes what generate would do without contacting a provider or touching the index.
+func printGeneratePlan(paths []string, idx *types.Index, registry *skeleton.Registry, cfg *types.Config, opts generateOptions) {
+	target := "prompt file"
+	if opts.send {
+		target = "provider"
+		if cfg.Provider != nil {
+			target = fmt.Sprintf("provider %s (model %s)", cfg.Provider.BaseURL, cfg.Provider.Model)
+		}
+	}
+
+	fmt.Println(display.Bold("Dry run: %d file(s) selected", len(paths)))
+	for _, path := range paths {
+		destination := target
+		if !opts.noLocal {
+			if _, ok := registry.Lookup(path, idx.Files[path].Type); ok {
+				destination = "local extractor"
+			}
+		}
+		fmt.Printf("  - %s → %s\n", path, destination)
+	}
+	fmt.Println(display.Info("No files or index entries were changed."))
+}
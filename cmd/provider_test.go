@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dakshpareek/ctx/internal/config"
+	"github.com/dakshpareek/ctx/internal/fs"
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+func configureProvider(t *testing.T, dir, baseURL string) {
+	t.Helper()
+	configPath := filepath.Join(dir, ".ctx", "config.json")
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	retries := 1
+	cfg.Provider = &types.ProviderConfig{BaseURL: baseURL, Model: "stub", MaxRetries: &retries}
+	if err := fs.WriteJSON(configPath, cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+}
+
+func TestGenerateSendWritesProviderReplies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if strings.Contains(req.Messages[0].Content, "broken.ts") {
+			http.Error(w, "nope", http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"content": "```markdown\n**Skeleton**\n```\n"}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	writeTempFile(t, dir, "app.ts", "export const app = 1;\n")
+	writeTempFile(t, dir, "broken.ts", "export const broken = 1;\n")
+	_, _ = executeCommand(t, dir, "init")
+	configureProvider(t, dir, server.URL)
+
+	var runErr error
+	stdout := captureOutput(t, func() {
+		_, _, runErr = executeCommandAllowError(t, dir, "generate", "--send", "--concurrency", "2")
+	})
+	if runErr == nil || !strings.Contains(runErr.Error(), "1 file(s) failed") {
+		t.Fatalf("expected partial failure error, got %v\n%s", runErr, stdout)
+	}
+
+	idx := loadIndex(t, dir)
+	entry := idx.Files["app.ts"]
+	if entry.Status != types.StatusCurrent {
+		t.Fatalf("expected app.ts current, got %s", entry.Status)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, entry.SkeletonPath))
+	if err != nil {
+		t.Fatalf("read skeleton: %v", err)
+	}
+	if string(data) != "**Skeleton**\n" {
+		t.Fatalf("unexpected skeleton content %q", data)
+	}
+	if status := idx.Files["broken.ts"].Status; status != types.StatusMissing {
+		t.Fatalf("expected failed file to keep its status, got %s", status)
+	}
+}
+
+func TestGenerateDryRunLeavesIndexUntouched(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "main.go", "package main\n")
+	writeTempFile(t, dir, "app.ts", "export const app = 1;\n")
+	_, _ = executeCommand(t, dir, "init")
+	configureProvider(t, dir, "http://127.0.0.1:1")
+
+	stdout := execAndCaptureStdout(t, dir, "generate", "--send", "--local", "--dry-run")
+	if !strings.Contains(stdout, "main.go → local extractor") || !strings.Contains(stdout, "app.ts → provider") {
+		t.Fatalf("expected plan output, got:\n%s", stdout)
+	}
+
+	idx := loadIndex(t, dir)
+	for path, entry := range idx.Files {
+		if entry.Status != types.StatusMissing {
+			t.Fatalf("expected %s to stay missing, got %s", path, entry.Status)
+		}
+	}
+	if fs.Exists(filepath.Join(dir, ".ctx", "prompt.md")) {
+		t.Fatalf("expected no prompt to be written in dry-run mode")
+	}
+}
+
+func TestGenerateSendKeepsRepliesWhenInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if strings.Contains(req.Messages[0].Content, "slow.ts") {
+			cancel()
+			<-r.Context().Done()
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"content": "```markdown\n**Skeleton**\n```\n"}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	writeTempFile(t, dir, "app.ts", "export const app = 1;\n")
+	writeTempFile(t, dir, "slow.ts", "export const slow = 1;\n")
+	writeTempFile(t, dir, "zed.ts", "export const zed = 1;\n")
+	_, _ = executeCommand(t, dir, "init")
+	configureProvider(t, dir, server.URL)
+
+	restore := changeDir(t, dir)
+	defer restore()
+
+	var runErr error
+	stdout := captureOutput(t, func() {
+		runErr = runGenerate(ctx, generateOptions{send: true, concurrency: 1})
+	})
+	if runErr == nil || !strings.Contains(runErr.Error(), "interrupted") {
+		t.Fatalf("expected interrupted error, got %v\n%s", runErr, stdout)
+	}
+
+	idx := loadIndex(t, dir)
+	if status := idx.Files["app.ts"].Status; status != types.StatusCurrent {
+		t.Fatalf("expected reply received before the interrupt to be saved, got %s", status)
+	}
+	for _, path := range []string{"slow.ts", "zed.ts"} {
+		if status := idx.Files[path].Status; status != types.StatusMissing {
+			t.Fatalf("expected %s to keep its status, got %s", path, status)
+		}
+	}
+}
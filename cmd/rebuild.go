@@ -20,6 +20,7 @@ import (
 
 type rebuildOptions struct {
 	confirm bool
+	jobs    int
 }
 
 func newRebuildCmd() *cobra.Command {
@@ -29,11 +30,14 @@ func newRebuildCmd() *cobra.Command {
 		Use:   "rebuild",
 		Short: "Reset index and skeletons (destructive)",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runRebuild(opts)
+			return withWorkspaceLock(cmd.CommandPath(), func() error {
+				return runRebuild(opts)
+			})
 		},
 	}
 
 	cmd.Flags().BoolVar(&opts.confirm, "confirm", false, "confirm rebuild and proceed without prompt")
+	cmd.Flags().IntVar(&opts.jobs, "jobs", 0, "number of files to scan and hash in parallel (default: number of CPUs)")
 
 	return cmd
 }
@@ -67,46 +71,69 @@ func runRebuild(opts rebuildOptions) error {
 	if err := fs.EnsureDir(skeletonDir); err != nil {
 		return &types.Error{Code: types.ExitCodeFileSystem, Err: err}
 	}
+	if err := os.RemoveAll(filepath.Join(wd, filepath.FromSlash(skeleton.SnapshotDirRoot))); err != nil {
+		return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("delete snapshots: %w", err)}
+	}
+
+	if err := hash.RemoveCache(filepath.Join(ctxDir, hash.CacheFileName)); err != nil {
+		return &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+	}
 
 	fmt.Println(display.Warning("Rebuilding context..."))
 
+	// Record the commit and time the scan starts from, so commits and edits made while it runs are picked up by the next sync.
+	head, started := headCommit(), time.Now().UTC()
+
+	root := sourceRoot(wd, *cfg)
 	scanCfg := *cfg
-	scanCfg.RootPath = "."
+	scanCfg.RootPath = root
 
-	files, err := scanner.ScanFiles(scanCfg)
+	cache := openHashCache(ctxDir, false)
+	defer saveHashCache(cache)
+
+	scanned, err := scanner.ScanCached(scanCfg, opts.jobs, cache)
 	if err != nil {
 		return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("scan files: %w", err)}
 	}
+	files := scanned.Files
+
+	typeRules, err := scanner.CompileTypeRules(cfg.FileTypes)
+	if err != nil {
+		return &types.Error{Code: types.ExitCodeData, Err: err}
+	}
 
 	idx := index.CreateEmptyIndex()
 	idx.Config = *cfg
+	idx.Skipped = scanned.Skipped
 
-	for _, relPath := range files {
-		fullPath := filepath.Join(wd, relPath)
-		info, err := os.Stat(fullPath)
-		if err != nil {
-			return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("stat %s: %w", relPath, err)}
-		}
+	states, err := hashFiles(root, files, cache, opts.jobs)
+	if err != nil {
+		return err
+	}
 
-		hashValue, err := hash.HashFile(fullPath)
-		if err != nil {
-			return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("hash %s: %w", relPath, err)}
+	for i, relPath := range files {
+		state := states[i]
+		if state.missing {
+			return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("stat %s: %w", relPath, os.ErrNotExist)}
 		}
+		info := state.info
 
 		entry := types.FileEntry{
 			Path:         relPath,
-			Hash:         hashValue,
+			Hash:         state.hash,
 			SkeletonHash: "",
 			SkeletonPath: skeleton.PathForSource(relPath),
 			LastModified: info.ModTime().UTC(),
 			Status:       types.StatusMissing,
-			Type:         scanner.DetectFileType(relPath),
+			Type:         typeRules.Detect(os.DirFS(root), relPath),
 			Size:         info.Size(),
+			Package:      scanned.Packages[relPath],
 		}
 		idx.Files[relPath] = entry
 	}
 
-	idx.LastSync = time.Now().UTC()
+	idx.LastSyncedCommit = head
+	idx.LastSync = started
 	idx.Stats = index.CalculateStats(idx)
 
 	indexPath := filepath.Join(ctxDir, indexFileName)
@@ -118,6 +145,9 @@ func runRebuild(opts rebuildOptions) error {
 	fmt.Printf("  %s\n", display.Success("Reset index"))
 	fmt.Printf("  %s\n", display.Info("Scanning codebase..."))
 	fmt.Printf("Found %d files (all marked missing)\n", len(files))
+	if len(scanned.Skipped) > 0 {
+		fmt.Printf("Skipped %d generated, binary or oversized file(s) (see 'ctx status -v')\n", len(scanned.Skipped))
+	}
 	fmt.Println()
 	fmt.Println("Run 'ctx generate' to recreate skeletons.")
 
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/dakshpareek/ctx/internal/display"
+	"github.com/dakshpareek/ctx/internal/fs"
+	"github.com/dakshpareek/ctx/internal/git"
+	"github.com/dakshpareek/ctx/internal/hash"
+	"github.com/dakshpareek/ctx/internal/skeleton"
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+// rename is a tracked file that moved from one path to another.
+type rename struct {
+	from string
+	to   string
+}
+
+// findRenames pairs deleted entries with added files. Files with identical content pair first, in path order;
+// the remaining pairs come from the renames git reports, keyed by new path and relative to the root.
+func findRenames(idx *types.Index, added, deleted []string, gitRenames map[string]string) []rename {
+	sort.Strings(added)
+	sort.Strings(deleted)
+
+	byHash := make(map[string][]string)
+	for _, path := range deleted {
+		h := idx.Files[path].Hash
+		byHash[h] = append(byHash[h], path)
+	}
+
+	var (
+		renames   []rename
+		unmatched []string
+		paired    = make(map[string]bool)
+	)
+	for _, path := range added {
+		candidates := byHash[idx.Files[path].Hash]
+		if len(candidates) == 0 {
+			unmatched = append(unmatched, path)
+			continue
+		}
+		renames = append(renames, rename{from: candidates[0], to: path})
+		paired[candidates[0]] = true
+		byHash[idx.Files[path].Hash] = candidates[1:]
+	}
+
+	isDeleted := make(map[string]bool, len(deleted))
+	for _, path := range deleted {
+		isDeleted[path] = true
+	}
+	for _, path := range unmatched {
+		from, ok := gitRenames[path]
+		if !ok || !isDeleted[from] || paired[from] {
+			continue
+		}
+		renames = append(renames, rename{from: from, to: path})
+		paired[from] = true
+	}
+
+	return renames
+}
+
+// gitRenamesSince returns git's renames since commit rebased onto the root, or nil when git cannot tell.
+func gitRenamesSince(commit, wd, root string) map[string]string {
+	if commit == "" || !git.IsGitRepo() {
+		return nil
+	}
+	prefix, ok := rootPrefix(wd, root)
+	if !ok {
+		return nil
+	}
+	reported, err := git.GetRenamesSince(commit)
+	if err != nil {
+		return nil
+	}
+
+	renames := make(map[string]string, len(reported))
+	for to, from := range reported {
+		relTo, okTo := relativeToRoot(filepath.ToSlash(to), prefix)
+		relFrom, okFrom := relativeToRoot(filepath.ToSlash(from), prefix)
+		if okTo && okFrom {
+			renames[relTo] = relFrom
+		}
+	}
+	return renames
+}
+
+// renamedEntry is the entry for r.from carried over to the freshly scanned entry at r.to. It keeps its status when
+// the content is unchanged and becomes stale otherwise; its skeleton path is left as it was.
+func renamedEntry(idx *types.Index, r rename) types.FileEntry {
+	old := idx.Files[r.from]
+	scanned := idx.Files[r.to]
+
+	entry := old
+	entry.Path = r.to
+	entry.Hash = scanned.Hash
+	entry.LastModified = scanned.LastModified
+	entry.Size = scanned.Size
+	entry.Type = scanned.Type
+	if old.Hash != scanned.Hash && entry.Status != types.StatusMissing {
+		entry.Status = types.StatusStale
+	}
+	return entry
+}
+
+// skeletonMove is the on-disk half of a rename: the skeleton rewritten for its new path, and the source snapshot.
+// Moves are applied once the index is saved, so a failed sync leaves the workspace as it was.
+type skeletonMove struct {
+	from     string
+	to       string
+	content  []byte
+	snapshot rename
+}
+
+// moveEntry replaces the entries for r with renamedEntry pointing at the skeleton path for r.to, and returns the
+// move that carries the skeleton and source snapshot along, rewriting the skeleton's File line. A skeleton already
+// at the new path is not overwritten; the entry then keeps its existing skeleton.
+func moveEntry(cwd string, idx *types.Index, r rename) (skeletonMove, error) {
+	old := idx.Files[r.from]
+	entry := renamedEntry(idx, r)
+
+	move := skeletonMove{from: old.SkeletonPath, to: skeleton.PathForSource(r.to)}
+	if move.from == "" {
+		move.from = skeleton.PathForSource(r.from)
+	}
+	target := filepath.Join(cwd, filepath.FromSlash(move.to))
+	if move.from != move.to && fs.Exists(target) {
+		fmt.Println(display.Warning("%s already exists; %s keeps its skeleton at %s", move.to, r.to, move.from))
+		entry.SkeletonPath = move.from
+		move.from, move.to = "", ""
+	} else {
+		entry.SkeletonPath = move.to
+		data, err := os.ReadFile(filepath.Join(cwd, filepath.FromSlash(move.from)))
+		switch {
+		case err == nil:
+			move.content = []byte(skeleton.RewriteSourcePath(string(data), r.from, r.to))
+			if hash.HashContent(data) == old.SkeletonHash {
+				entry.SkeletonHash = hash.HashContent(move.content)
+			}
+		case os.IsNotExist(err):
+			move.from, move.to = "", ""
+		default:
+			return move, &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("read %s: %w", move.from, err)}
+		}
+	}
+
+	snapshot := rename{from: skeleton.SnapshotPathForSource(r.from), to: skeleton.SnapshotPathForSource(r.to)}
+	if fs.Exists(filepath.Join(cwd, filepath.FromSlash(snapshot.from))) && !fs.Exists(filepath.Join(cwd, filepath.FromSlash(snapshot.to))) {
+		move.snapshot = snapshot
+	}
+
+	delete(idx.Files, r.from)
+	idx.Files[r.to] = entry
+	return move, nil
+}
+
+// apply writes the moved skeleton and snapshot under cwd and removes the originals.
+func (m skeletonMove) apply(cwd string) error {
+	if m.to != "" {
+		if err := fs.WriteFile(filepath.Join(cwd, filepath.FromSlash(m.to)), m.content); err != nil {
+			return &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+		}
+		if err := os.Remove(filepath.Join(cwd, filepath.FromSlash(m.from))); err != nil {
+			return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("remove %s: %w", m.from, err)}
+		}
+	}
+
+	if m.snapshot.from != "" {
+		target := filepath.Join(cwd, filepath.FromSlash(m.snapshot.to))
+		if err := fs.EnsureDir(filepath.Dir(target)); err != nil {
+			return &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+		}
+		if err := os.Rename(filepath.Join(cwd, filepath.FromSlash(m.snapshot.from)), target); err != nil {
+			return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("move snapshot %s: %w", m.snapshot.from, err)}
+		}
+	}
+	return nil
+}
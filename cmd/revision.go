@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	stdfs "io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/dakshpareek/ctx/internal/config"
+	"github.com/dakshpareek/ctx/internal/git"
+	"github.com/dakshpareek/ctx/internal/index"
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+// revision is the tree of a git revision that --rev reads instead of the working tree.
+type revision struct {
+	name   string
+	commit string
+	tree   *git.Tree
+	// root is the slash-separated path of the configured source root in tree, and source its files.
+	root   string
+	source stdfs.FS
+}
+
+// openRevision resolves name and locates the source root inside its tree.
+func openRevision(name, root string) (*revision, error) {
+	tree, err := git.OpenTree(name)
+	if err != nil {
+		switch {
+		case errors.Is(err, git.ErrNotGit):
+			return nil, &types.Error{Code: types.ExitCodeUserError, Err: fmt.Errorf("--rev requires a git repository")}
+		case errors.Is(err, git.ErrUnknownRevision):
+			return nil, &types.Error{Code: types.ExitCodeUserError, Err: err}
+		}
+		return nil, &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+	}
+
+	top, err := git.TopLevel()
+	if err != nil {
+		return nil, &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+	}
+	// git reports the top with symlinks resolved, so resolve the root the same way before comparing.
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(top); err == nil {
+		top = resolved
+	}
+	rel, err := filepath.Rel(top, root)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, &types.Error{Code: types.ExitCodeUserError, Err: fmt.Errorf("source root %s is outside the git repository %s", root, top)}
+	}
+
+	rel = filepath.ToSlash(rel)
+	source, err := stdfs.Sub(tree, rel)
+	if err != nil {
+		return nil, &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+	}
+
+	return &revision{name: name, commit: tree.Commit(), tree: tree, root: rel, source: source}, nil
+}
+
+// syncToRevision updates idx in memory to describe the git revision name, as a sync against it would, and returns
+// the revision, whose tree is closed by then. The workspace is left untouched; idx must not be saved.
+func syncToRevision(wd, ctxDir string, idx *types.Index, name string) (*revision, error) {
+	cfg, err := config.LoadConfig(filepath.Join(ctxDir, configFileName))
+	if err != nil {
+		return nil, &types.Error{Code: types.ExitCodeData, Err: err}
+	}
+
+	rev, err := openRevision(name, sourceRoot(wd, *cfg))
+	if err != nil {
+		return nil, err
+	}
+	defer rev.tree.Close()
+	if _, err := syncIndex(wd, ctxDir, cfg, idx, rev, syncOptions{}); err != nil {
+		return nil, err
+	}
+	idx.LastSyncedCommit = rev.commit
+	idx.Stats = index.CalculateStats(idx)
+	return rev, nil
+}
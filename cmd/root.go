@@ -39,6 +39,7 @@ snapshot of your project for AI-assisted development.`,
 	coreCommands := []*cobra.Command{
 		newInitCmd(),
 		newAskCmd(),
+		newApplyCmd(),
 		newUpdateCmd(),
 		newBundleCmd(),
 		newStatusCmd(),
@@ -57,6 +58,7 @@ snapshot of your project for AI-assisted development.`,
 		newExportCmd(),
 		newCleanCmd(),
 		newRebuildCmd(),
+		newHooksCmd(),
 	}
 
 	for _, advancedCmd := range advancedCommands {
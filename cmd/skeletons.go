@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	stdfs "io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/dakshpareek/ctx/internal/display"
+	"github.com/dakshpareek/ctx/internal/fs"
+	"github.com/dakshpareek/ctx/internal/hash"
+	"github.com/dakshpareek/ctx/internal/scanner"
+	"github.com/dakshpareek/ctx/internal/skeleton"
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+// saveSkeleton writes skeleton content for the entry and marks it current with the new skeleton hash.
+// Skeletons live under cwd; the source is read from root. If the source no longer matches the hash recorded
+// at the last sync, the skeleton describes older content and the entry is left stale.
+func saveSkeleton(cwd, root string, entry types.FileEntry, content string) (types.FileEntry, error) {
+	if entry.SkeletonPath == "" {
+		entry.SkeletonPath = skeleton.PathForSource(entry.Path)
+	}
+
+	target := filepath.Join(cwd, filepath.FromSlash(entry.SkeletonPath))
+	data := []byte(content)
+	if err := fs.WriteFile(target, data); err != nil {
+		return entry, &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+	}
+
+	entry.SkeletonHash = hash.HashContent(data)
+	entry.Status = types.StatusCurrent
+	return recordSourceSnapshot(cwd, root, entry)
+}
+
+// recordSourceSnapshot copies the current source into .ctx/snapshots so later prompts can diff against it,
+// and records the structural hash used to recognise cosmetic edits. Both are only recorded when the source
+// still matches entry.Hash; otherwise they would vouch for edits the skeleton never saw.
+func recordSourceSnapshot(cwd, root string, entry types.FileEntry) (types.FileEntry, error) {
+	content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(entry.Path)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entry, nil
+		}
+		return entry, &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("read %s: %w", entry.Path, err)}
+	}
+
+	sourceHash := hash.HashContent(content)
+	if entry.Hash != "" && sourceHash != entry.Hash {
+		entry.Status = types.StatusStale
+		entry.SourceSnapshotHash = ""
+		entry.StructuralHash = ""
+		return entry, nil
+	}
+
+	target := filepath.Join(cwd, filepath.FromSlash(skeleton.SnapshotPathForSource(entry.Path)))
+	if err := fs.WriteFile(target, content); err != nil {
+		return entry, &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+	}
+
+	entry.SourceSnapshotHash = sourceHash
+	entry.StructuralHash = hash.Structural(entry.Path, content)
+	return entry, nil
+}
+
+// cosmeticChange reports whether a current entry's source, read from source, changed only in ways its skeleton
+// does not describe. It is always false unless semanticHash is enabled and a structural hash was recorded when the
+// skeleton became current.
+func cosmeticChange(source stdfs.FS, entry types.FileEntry, cfg types.Config) bool {
+	if !cfg.SemanticHash || entry.Status != types.StatusCurrent || entry.StructuralHash == "" {
+		return false
+	}
+	content, err := stdfs.ReadFile(source, entry.Path)
+	if err != nil {
+		return false
+	}
+	return cosmeticContent(entry, cfg, content)
+}
+
+// cosmeticContent is cosmeticChange for source content that is already loaded, such as a staged blob.
+func cosmeticContent(entry types.FileEntry, cfg types.Config, content []byte) bool {
+	if !cfg.SemanticHash || entry.Status != types.StatusCurrent || entry.StructuralHash == "" {
+		return false
+	}
+	return hash.Structural(entry.Path, content) == entry.StructuralHash
+}
+
+// previousSourceSnapshot returns the snapshot recorded when the skeleton was last current, if it is intact.
+func previousSourceSnapshot(cwd string, entry types.FileEntry) ([]byte, bool) {
+	if entry.SourceSnapshotHash == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(filepath.Join(cwd, filepath.FromSlash(skeleton.SnapshotPathForSource(entry.Path))))
+	if err != nil || hash.HashContent(data) != entry.SourceSnapshotHash {
+		return nil, false
+	}
+	return data, true
+}
+
+// extractorRegistry returns the built-in extractors plus any command extractors declared in config.
+func extractorRegistry(cfg types.Config) *skeleton.Registry {
+	registry := skeleton.DefaultRegistry()
+	for key, command := range cfg.Extractors {
+		registry.Register(key, skeleton.CommandExtractor{Command: command})
+	}
+	return registry
+}
+
+// stampTemplate records the prompt version and template hash an entry's skeleton is generated from.
+func stampTemplate(entry types.FileEntry, templates *skeleton.PromptTemplates, version string) types.FileEntry {
+	entry.PromptVersion = version
+	entry.TemplateHash = templateHash(entry, templates)
+	return entry
+}
+
+// outdatedTemplate reports whether a skeleton was generated from another prompt version or template.
+func outdatedTemplate(entry types.FileEntry, templates *skeleton.PromptTemplates, version string) bool {
+	if entry.PromptVersion != "" && entry.PromptVersion != version {
+		return true
+	}
+	return entry.TemplateHash != "" && entry.TemplateHash != templateHash(entry, templates)
+}
+
+func templateHash(entry types.FileEntry, templates *skeleton.PromptTemplates) string {
+	_, template := templates.Lookup(entry.Path, fileTypeFor(entry.Path, entry))
+	return hash.HashContent([]byte(template))
+}
+
+// fileTypeFor returns the recorded file type, detecting it for entries indexed before types were tracked.
+func fileTypeFor(path string, entry types.FileEntry) string {
+	if entry.Type != "" {
+		return entry.Type
+	}
+	return scanner.DetectFileType(path)
+}
+
+// extractLocalSkeletons produces skeletons for files with a registered extractor without an AI round-trip.
+// It returns the paths that still need a prompt and the paths handled locally.
+// Extracted entries record the prompt version but no template hash, since extractors ignore the template.
+func extractLocalSkeletons(paths []string, idx *types.Index, registry *skeleton.Registry, cwd, root, version string) ([]string, []string, error) {
+	var (
+		remaining []string
+		extracted []string
+	)
+
+	for _, path := range paths {
+		extractor, ok := registry.Lookup(path, fileTypeFor(path, idx.Files[path]))
+		if !ok {
+			remaining = append(remaining, path)
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(path)))
+		if err != nil {
+			return nil, nil, &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("read %s: %w", path, err)}
+		}
+
+		rendered, err := extractor.Extract(path, content)
+		if err != nil {
+			fmt.Println(display.Warning("Falling back to prompt for %s: %v", path, err))
+			remaining = append(remaining, path)
+			continue
+		}
+
+		entry, err := saveSkeleton(cwd, root, idx.Files[path], rendered)
+		if err != nil {
+			return nil, nil, err
+		}
+		entry.PromptVersion = version
+		entry.TemplateHash = ""
+		idx.Files[path] = entry
+		extracted = append(extracted, path)
+	}
+
+	return remaining, extracted, nil
+}
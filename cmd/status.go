@@ -19,6 +19,7 @@ import (
 type statusOptions struct {
 	verbose bool
 	asJSON  bool
+	rev     string
 }
 
 func newStatusCmd() *cobra.Command {
@@ -34,6 +35,7 @@ func newStatusCmd() *cobra.Command {
 
 	cmd.Flags().BoolVarP(&opts.verbose, "verbose", "v", false, "list stale and missing files")
 	cmd.Flags().BoolVar(&opts.asJSON, "json", false, "output status as JSON")
+	cmd.Flags().StringVar(&opts.rev, "rev", "", "report skeleton coverage for a git revision instead of the working tree")
 
 	return cmd
 }
@@ -59,6 +61,14 @@ func runStatus(opts statusOptions) error {
 		return &types.Error{Code: types.ExitCodeData, Err: err}
 	}
 
+	var rev *revision
+	if opts.rev != "" {
+		rev, err = syncToRevision(wd, ctxDir, idx, opts.rev)
+		if err != nil {
+			return err
+		}
+	}
+
 	idx.Stats = index.CalculateStats(idx)
 
 	if opts.asJSON {
@@ -71,6 +81,9 @@ func runStatus(opts statusOptions) error {
 	}
 
 	fmt.Println(display.Bold("Code Context Status"))
+	if rev != nil {
+		fmt.Printf("Revision: %s (%s)\n", rev.name, shortCommit(rev.commit))
+	}
 	fmt.Println()
 
 	stats := idx.Stats
@@ -83,8 +96,18 @@ func runStatus(opts statusOptions) error {
 	if stats.PendingGeneration > 0 {
 		fmt.Printf("  %s\n", display.Info("%d pending generation", stats.PendingGeneration))
 	}
+	if len(idx.Skipped) > 0 {
+		fmt.Printf("  %d skipped (generated, binary or oversized)\n", len(idx.Skipped))
+	}
+
+	if len(idx.PackageStats) > 0 {
+		fmt.Println("\nPackages:")
+		for _, name := range sortedPackages(idx.PackageStats) {
+			fmt.Printf("  %s: %s\n", name, describePackageStats(idx.PackageStats[name]))
+		}
+	}
 
-	if !idx.LastSync.IsZero() {
+	if rev == nil && !idx.LastSync.IsZero() {
 		fmt.Printf("\nLast sync: %s\n", humanizeDuration(time.Since(idx.LastSync)))
 	}
 
@@ -97,6 +120,21 @@ func runStatus(opts statusOptions) error {
 	return nil
 }
 
+// sortedPackages returns package names in order.
+func sortedPackages(stats map[string]types.IndexStats) []string {
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func describePackageStats(stats types.IndexStats) string {
+	return fmt.Sprintf("%d files (%d current, %d stale, %d missing, %d pending)",
+		stats.TotalFiles, stats.Current, stats.Stale, stats.Missing, stats.PendingGeneration)
+}
+
 func displayWithWarning(count int, label string) string {
 	if count > 0 {
 		return display.Warning("%d %s", count, label)
@@ -143,6 +181,13 @@ func printStatusLists(idx *types.Index) {
 	printList("Stale", stale)
 	printList("Missing", missing)
 	printList("Pending generation", pending)
+
+	skipped := make([]string, 0, len(idx.Skipped))
+	for path, reason := range idx.Skipped {
+		skipped = append(skipped, fmt.Sprintf("%s (%s)", path, reason))
+	}
+	sort.Strings(skipped)
+	printList("Skipped", skipped)
 }
 
 func printList(label string, items []string) {
@@ -1,8 +1,15 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/dakshpareek/ctx/internal/config"
+	"github.com/dakshpareek/ctx/internal/fs"
+	"github.com/dakshpareek/ctx/internal/types"
 )
 
 func TestHumanizeDuration(t *testing.T) {
@@ -22,3 +29,87 @@ func TestHumanizeDuration(t *testing.T) {
 		}
 	}
 }
+
+func TestStatusVerboseListsSkippedFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "main.go", "package main\n")
+	writeTempFile(t, dir, "api/user.pb.go", "package api\n")
+	writeTempFile(t, dir, "mocks/mock.go", "// Code generated by MockGen. DO NOT EDIT.\n\npackage mocks\n")
+	writeTempFile(t, dir, "web/vendor.min.js", "var a=1;\n")
+	_, _ = executeCommand(t, dir, "init")
+
+	idx := loadIndex(t, dir)
+	if len(idx.Files) != 1 {
+		t.Fatalf("expected only main.go to be tracked, got %v", idx.Files)
+	}
+
+	stdout := execAndCaptureStdout(t, dir, "status", "-v")
+	for _, want := range []string{
+		"3 skipped (generated, binary or oversized)",
+		"Skipped:\n  - api/user.pb.go (protobuf)\n  - mocks/mock.go (generated)\n  - web/vendor.min.js (minified)\n",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Fatalf("expected %q in status output:\n%s", want, stdout)
+		}
+	}
+}
+
+func TestStatusAndBundleReportPerPackage(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "services/api/main.go", "package main\n")
+	writeTempFile(t, dir, "services/api/web/app.ts", "export const app = 1;\n")
+	writeTempFile(t, dir, "packages/ui/button.ts", "export const button = 1;\n")
+	writeTempFile(t, dir, "packages/ui/theme.ts", "export const theme = 1;\n")
+	writeTempFile(t, dir, "tools/gen.go", "package tools\n")
+	_, _ = executeCommand(t, dir, "init")
+
+	configPath := filepath.Join(dir, ".ctx", "config.json")
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	cfg.Packages = []types.PackageConfig{
+		{Roots: []string{"services/*"}, IncludedExtensions: []string{".go"}},
+		{Roots: []string{"packages/*"}, IncludedExtensions: []string{".ts"}},
+	}
+	if err := fs.WriteJSON(configPath, cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	_ = execAndCaptureStdout(t, dir, "sync", "--full")
+	idx := loadIndex(t, dir)
+	if len(idx.Files) != 3 {
+		t.Fatalf("expected only package files to be tracked, got %v", idx.Files)
+	}
+	if pkg := idx.Files["packages/ui/theme.ts"].Package; pkg != "packages/ui" {
+		t.Fatalf("expected package recorded on entry, got %q", pkg)
+	}
+
+	writeTempFile(t, dir, "response.md", "**Skeleton Path:** .ctx/skeletons/packages/ui/button.skeleton.ts\n```\n**button**\n```\n")
+	_ = execAndCaptureStdout(t, dir, "apply", "response.md")
+
+	stdout := execAndCaptureStdout(t, dir, "status")
+	for _, want := range []string{
+		"Packages:\n",
+		"  packages/ui: 2 files (1 current, 0 stale, 1 missing, 0 pending)\n",
+		"  services/api: 1 files (0 current, 0 stale, 1 missing, 0 pending)\n",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Fatalf("expected %q in status output:\n%s", want, stdout)
+		}
+	}
+
+	_ = execAndCaptureStdout(t, dir, "bundle")
+	bundle, err := os.ReadFile(filepath.Join(dir, ".ctx", "context.md"))
+	if err != nil {
+		t.Fatalf("read bundle: %v", err)
+	}
+	for _, want := range []string{
+		"## Packages\n\n- packages/ui: 2 files (1 current, 0 stale, 1 missing, 0 pending)\n- services/api:",
+		"### packages/ui/button.ts\n**Package:** packages/ui\n",
+	} {
+		if !strings.Contains(string(bundle), want) {
+			t.Fatalf("expected %q in bundle:\n%s", want, bundle)
+		}
+	}
+}
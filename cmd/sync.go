@@ -3,9 +3,12 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	stdfs "io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -22,8 +25,11 @@ import (
 )
 
 type syncOptions struct {
-	full    bool
-	verbose bool
+	full     bool
+	verbose  bool
+	paranoid bool
+	jobs     int
+	rev      string
 }
 
 func newSyncCmd() *cobra.Command {
@@ -35,12 +41,17 @@ func newSyncCmd() *cobra.Command {
 		Long:  advancedDescription,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			printAdvancedNotice("ctx ask")
-			return runSync(opts)
+			return withWorkspaceLock(cmd.CommandPath(), func() error {
+				return runSync(opts)
+			})
 		},
 	}
 
 	cmd.Flags().BoolVar(&opts.full, "full", false, "force full scan (ignore git diff)")
 	cmd.Flags().BoolVarP(&opts.verbose, "verbose", "v", false, "show detailed file changes")
+	cmd.Flags().BoolVar(&opts.paranoid, "paranoid", false, "rehash every file instead of trusting unchanged size and modification time")
+	cmd.Flags().IntVar(&opts.jobs, "jobs", 0, "number of files to scan and hash in parallel (default: number of CPUs)")
+	cmd.Flags().StringVar(&opts.rev, "rev", "", "compare the index with a git revision instead of the working tree, without saving it")
 
 	return cmd
 }
@@ -74,48 +85,197 @@ func runSync(opts syncOptions) error {
 		return &types.Error{Code: types.ExitCodeData, Err: err}
 	}
 
-	scanCfg := *cfg
-	scanCfg.RootPath = "."
+	// Record the commit and time the scan starts from, so commits and edits made while it runs are picked up next time.
+	var (
+		rev     *revision
+		head    string
+		started = time.Now().UTC()
+	)
+	if opts.rev != "" {
+		rev, err = openRevision(opts.rev, sourceRoot(wd, *cfg))
+		if err != nil {
+			return err
+		}
+		defer rev.tree.Close()
+		fmt.Println(display.Info("Scanning %s (%s)...", rev.name, shortCommit(rev.commit)))
+	} else {
+		head = headCommit()
+		fmt.Println(display.Info("Scanning codebase..."))
+	}
 
-	fmt.Println(display.Info("Scanning codebase..."))
-	files, err := scanner.ScanFiles(scanCfg)
+	changes, err := syncIndex(wd, ctxDir, cfg, idx, rev, opts)
 	if err != nil {
-		return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("scan files: %w", err)}
+		return err
 	}
-	fmt.Println(display.Success("%d files scanned", len(files)))
+	fmt.Println(display.Success("%d files scanned", changes.scanned))
 
-	fileSet := make(map[string]struct{}, len(files))
-	for _, f := range files {
-		fileSet[f] = struct{}{}
+	idx.Stats = index.CalculateStats(idx)
+	if rev == nil {
+		idx.LastSyncedCommit = head
+		idx.LastSync = started
+		if err := index.SaveIndex(idx, indexPath); err != nil {
+			return &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+		}
+		for _, move := range changes.moves {
+			if err := move.apply(wd); err != nil {
+				return err
+			}
+		}
+	}
+
+	if changes.empty() {
+		fmt.Println(display.Success("No changes detected"))
+	} else {
+		fmt.Println(display.Bold("Changes detected:"))
+		if len(changes.modified) > 0 {
+			fmt.Printf("  • %d modified (marked stale)\n", len(changes.modified))
+		}
+		if len(changes.added) > 0 {
+			fmt.Printf("  • %d new file(s) (marked missing)\n", len(changes.added))
+		}
+		if len(changes.deleted) > 0 {
+			fmt.Printf("  • %d deleted\n", len(changes.deleted))
+		}
+		if len(changes.renamed) > 0 {
+			if rev == nil {
+				fmt.Printf("  • %d renamed (skeletons moved)\n", len(changes.renamed))
+			} else {
+				fmt.Printf("  • %d renamed\n", len(changes.renamed))
+			}
+		}
+		if len(changes.outdated) > 0 {
+			fmt.Printf("  • %d skeleton(s) from an older prompt template (marked stale)\n", len(changes.outdated))
+		}
+		if len(changes.skipped) > 0 {
+			fmt.Printf("  • %d now skipped as generated, binary or oversized (removed)\n", len(changes.skipped))
+		}
+		if len(changes.cosmetic) > 0 {
+			fmt.Printf("  • %d cosmetic change(s) (kept current)\n", len(changes.cosmetic))
+		}
+	}
+
+	if opts.verbose {
+		printDetailedChanges("Modified", changes.modified)
+		printDetailedChanges("Added", changes.added)
+		printDetailedChanges("Deleted", changes.deleted)
+		printDetailedChanges("Renamed", changes.renamed)
+		printDetailedChanges("Outdated template", changes.outdated)
+		printDetailedChanges("Cosmetic", changes.cosmetic)
+		printDetailedChanges("Skipped", changes.skipped)
+	}
+
+	fmt.Println()
+	fmt.Println(display.Bold("Status:"))
+	stats := idx.Stats
+	fmt.Println(display.Success("%d current", stats.Current))
+	if stats.Stale > 0 {
+		fmt.Println(display.Warning("%d stale", stats.Stale))
+	} else {
+		fmt.Println(display.Success("0 stale"))
+	}
+	if stats.Missing > 0 {
+		fmt.Println(display.Warning("%d missing", stats.Missing))
+	} else {
+		fmt.Println(display.Success("0 missing"))
 	}
+	if stats.PendingGeneration > 0 {
+		fmt.Println(display.Info("%d pending generation", stats.PendingGeneration))
+	}
+	fmt.Printf("  Total tracked: %d\n", stats.TotalFiles)
 
-	rootDir := wd
+	if rev != nil {
+		fmt.Println()
+		fmt.Println(display.Info("Index not saved: --rev reports what syncing to %s would change", rev.name))
+	}
 
-	updateSet := determineUpdateSet(files, opts.full, idx.LastSync, rootDir, scanCfg)
+	return nil
+}
+
+// syncChanges lists the paths a sync changed in the index, by kind, and how many files it scanned.
+type syncChanges struct {
+	scanned  int
+	modified []string
+	added    []string
+	deleted  []string
+	renamed  []string
+	outdated []string
+	cosmetic []string
+	skipped  []string
+	// moves carry skeletons along with renamed files; they are applied after the index is saved.
+	moves []skeletonMove
+}
 
+func (c syncChanges) empty() bool {
+	return len(c.modified)+len(c.added)+len(c.deleted)+len(c.renamed)+len(c.outdated)+len(c.cosmetic)+len(c.skipped) == 0
+}
+
+// syncIndex updates idx to match the source files: the working tree, or rev when it is not nil. For the working
+// tree the entries of renamed files point at their new skeleton paths, and changes.moves carry the skeletons along;
+// a revision leaves the workspace untouched, so its renamed entries keep pointing at their existing skeletons.
+// Saving idx, then applying the moves, is up to the caller.
+func syncIndex(wd, ctxDir string, cfg *types.Config, idx *types.Index, rev *revision, opts syncOptions) (syncChanges, error) {
+	var changes syncChanges
+
+	rootDir := sourceRoot(wd, *cfg)
 	var (
-		modified []string
-		added    []string
+		scanned scanner.Result
+		source  stdfs.FS
+		cache   *hash.Cache
+		err     error
 	)
+	if rev != nil {
+		scanned, err = scanner.ScanTree(rev.tree, rev.root, *cfg, opts.jobs)
+		source = rev.source
+	} else {
+		cache = openHashCache(ctxDir, opts.paranoid)
+		defer saveHashCache(cache)
+		scanCfg := *cfg
+		scanCfg.RootPath = rootDir
+		scanned, err = scanner.ScanCached(scanCfg, opts.jobs, cache)
+		source = os.DirFS(rootDir)
+	}
+	if err != nil {
+		return changes, &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("scan files: %w", err)}
+	}
+	files := scanned.Files
+	changes.scanned = len(files)
+
+	typeRules, err := scanner.CompileTypeRules(cfg.FileTypes)
+	if err != nil {
+		return changes, &types.Error{Code: types.ExitCodeData, Err: err}
+	}
 
+	fileSet := make(map[string]struct{}, len(files))
+	for _, f := range files {
+		fileSet[f] = struct{}{}
+	}
+
+	// The recorded sync describes the working tree, so every file of a revision is hashed.
+	updateSet := determineUpdateSet(files, opts.full || rev != nil, idx.LastSync, idx.LastSyncedCommit, wd, *cfg)
+
+	paths := make([]string, 0, len(updateSet))
 	for path := range updateSet {
-		if _, ok := fileSet[path]; !ok {
-			continue
+		if _, ok := fileSet[path]; ok {
+			paths = append(paths, path)
 		}
+	}
+	sort.Strings(paths)
 
-		fullPath := filepath.Join(rootDir, filepath.FromSlash(path))
-		info, err := os.Stat(fullPath)
-		if err != nil {
-			if os.IsNotExist(err) {
-				continue
-			}
-			return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("stat %s: %w", path, err)}
-		}
+	var states []fileState
+	if rev != nil {
+		states, err = hashSource(source, paths, opts.jobs)
+	} else {
+		states, err = hashFiles(rootDir, paths, cache, opts.jobs)
+	}
+	if err != nil {
+		return changes, err
+	}
 
-		hashValue, err := hash.HashFile(fullPath)
-		if err != nil {
-			return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("hash %s: %w", path, err)}
+	for i, path := range paths {
+		if states[i].missing {
+			continue
 		}
+		info, hashValue := states[i].info, states[i].hash
 
 		existing, ok := idx.Files[path]
 		if !ok {
@@ -126,23 +286,27 @@ func runSync(opts syncOptions) error {
 				SkeletonPath: skeleton.PathForSource(path),
 				LastModified: info.ModTime().UTC(),
 				Status:       types.StatusMissing,
-				Type:         scanner.DetectFileType(path),
+				Type:         typeRules.Detect(source, path),
 				Size:         info.Size(),
 			}
 			idx.Files[path] = entry
-			added = append(added, path)
+			changes.added = append(changes.added, path)
 			continue
 		}
 
 		if existing.Hash != hashValue {
 			existing.Hash = hashValue
-			existing.Status = types.StatusStale
-			modified = append(modified, path)
+			if cosmeticChange(source, existing, *cfg) {
+				changes.cosmetic = append(changes.cosmetic, path)
+			} else {
+				existing.Status = types.StatusStale
+				changes.modified = append(changes.modified, path)
+			}
 		}
 
 		existing.LastModified = info.ModTime().UTC()
 		existing.Size = info.Size()
-		existing.Type = scanner.DetectFileType(path)
+		existing.Type = typeRules.Detect(source, path)
 		existing.Path = path
 		if existing.SkeletonPath == "" {
 			existing.SkeletonPath = skeleton.PathForSource(path)
@@ -151,114 +315,137 @@ func runSync(opts syncOptions) error {
 		idx.Files[path] = existing
 	}
 
-	var deleted []string
 	for path := range idx.Files {
-		if _, ok := fileSet[path]; !ok {
-			deleted = append(deleted, path)
+		if _, ok := fileSet[path]; ok {
+			continue
+		}
+		if _, ok := scanned.Skipped[path]; ok {
+			changes.skipped = append(changes.skipped, path)
+		} else {
+			changes.deleted = append(changes.deleted, path)
 		}
-	}
-	for _, path := range deleted {
-		delete(idx.Files, path)
-	}
-
-	idx.LastSync = time.Now().UTC()
-	idx.Stats = index.CalculateStats(idx)
-
-	if err := index.SaveIndex(idx, indexPath); err != nil {
-		return &types.Error{Code: types.ExitCodeFileSystem, Err: err}
 	}
 
-	if len(modified)+len(added)+len(deleted) == 0 {
-		fmt.Println(display.Success("No changes detected"))
-	} else {
-		fmt.Println(display.Bold("Changes detected:"))
-		if len(modified) > 0 {
-			fmt.Printf("  • %d modified (marked stale)\n", len(modified))
-		}
-		if len(added) > 0 {
-			fmt.Printf("  • %d new file(s) (marked missing)\n", len(added))
+	if len(changes.added) > 0 && len(changes.deleted) > 0 {
+		var gitRenames map[string]string
+		if rev == nil {
+			gitRenames = gitRenamesSince(idx.LastSyncedCommit, wd, rootDir)
 		}
-		if len(deleted) > 0 {
-			fmt.Printf("  • %d deleted\n", len(deleted))
+		movedFrom := make(map[string]bool)
+		movedTo := make(map[string]bool)
+		for _, r := range findRenames(idx, changes.added, changes.deleted, gitRenames) {
+			if rev != nil {
+				entry := renamedEntry(idx, r)
+				delete(idx.Files, r.from)
+				idx.Files[r.to] = entry
+			} else {
+				move, err := moveEntry(wd, idx, r)
+				if err != nil {
+					return changes, err
+				}
+				changes.moves = append(changes.moves, move)
+			}
+			movedFrom[r.from], movedTo[r.to] = true, true
+			changes.renamed = append(changes.renamed, r.from+" → "+r.to)
 		}
+		changes.added = slices.DeleteFunc(changes.added, func(path string) bool { return movedTo[path] })
+		changes.deleted = slices.DeleteFunc(changes.deleted, func(path string) bool { return movedFrom[path] })
 	}
 
-	if opts.verbose {
-		printDetailedChanges("Modified", modified)
-		printDetailedChanges("Added", added)
-		printDetailedChanges("Deleted", deleted)
+	for _, path := range append(changes.deleted, changes.skipped...) {
+		delete(idx.Files, path)
 	}
-
-	fmt.Println()
-	fmt.Println(display.Bold("Status:"))
-	stats := idx.Stats
-	fmt.Println(display.Success("%d current", stats.Current))
-	if stats.Stale > 0 {
-		fmt.Println(display.Warning("%d stale", stats.Stale))
-	} else {
-		fmt.Println(display.Success("0 stale"))
+	idx.Skipped = scanned.Skipped
+	for path, entry := range idx.Files {
+		entry.Package = scanned.Packages[path]
+		idx.Files[path] = entry
 	}
-	if stats.Missing > 0 {
-		fmt.Println(display.Warning("%d missing", stats.Missing))
-	} else {
-		fmt.Println(display.Success("0 missing"))
+	if cache != nil {
+		cache.Retain(func(key string) bool {
+			if _, ok := idx.Files[key]; ok {
+				return true
+			}
+			if _, ok := idx.Skipped[key]; ok {
+				return true
+			}
+			return strings.HasPrefix(key, skeleton.DirRoot+"/")
+		})
 	}
-	if stats.PendingGeneration > 0 {
-		fmt.Println(display.Info("%d pending generation", stats.PendingGeneration))
+
+	templates, err := skeleton.LoadPromptTemplates(*cfg)
+	if err != nil {
+		return changes, &types.Error{Code: types.ExitCodeData, Err: err}
 	}
-	fmt.Printf("  Total tracked: %d\n", stats.TotalFiles)
 
-	return nil
+	for path, entry := range idx.Files {
+		if entry.Status != types.StatusCurrent || !outdatedTemplate(entry, templates, cfg.SkeletonPromptVersion) {
+			continue
+		}
+		entry.Status = types.StatusStale
+		idx.Files[path] = entry
+		changes.outdated = append(changes.outdated, path)
+	}
+	idx.PromptVersion = cfg.SkeletonPromptVersion
+	idx.Config = *cfg
+
+	return changes, nil
 }
 
-func determineUpdateSet(files []string, forceFull bool, lastSync time.Time, root string, cfg types.Config) map[string]struct{} {
+// determineUpdateSet picks the files to rehash: everything on a full sync, otherwise the files git reports as
+// changed since lastCommit (committed, staged or unstaged) plus untracked files, or files modified since lastSync
+// outside git. Without a usable lastCommit, as after a rebase, every file is rehashed. Git reports paths relative
+// to wd, so they are rebased onto the configured root; when the root lies outside wd every file is rehashed.
+func determineUpdateSet(files []string, forceFull bool, lastSync time.Time, lastCommit, wd string, cfg types.Config) map[string]struct{} {
 	set := make(map[string]struct{})
-	if forceFull {
+	all := func() map[string]struct{} {
 		for _, f := range files {
 			set[f] = struct{}{}
 		}
 		return set
 	}
+	if forceFull {
+		return all()
+	}
 
+	root := sourceRoot(wd, cfg)
 	if git.IsGitRepo() && !lastSync.IsZero() {
-		if modified, err := git.GetModifiedFiles(); err == nil {
-			for _, f := range modified {
-				normalized := filepath.ToSlash(f)
-				set[normalized] = struct{}{}
+		prefix, ok := rootPrefix(wd, root)
+		if !ok || lastCommit == "" {
+			return all()
+		}
+
+		add := func(paths []string) {
+			for _, f := range paths {
+				if rel, ok := relativeToRoot(filepath.ToSlash(f), prefix); ok {
+					set[rel] = struct{}{}
+				}
 			}
-		} else if !errors.Is(err, git.ErrNotGit) {
-			for _, f := range files {
-				set[f] = struct{}{}
+		}
+
+		changed, err := git.GetChangedFilesSince(lastCommit)
+		if err != nil {
+			if errors.Is(err, git.ErrUnknownCommit) {
+				fmt.Println(display.Warning("Last synced commit %s is no longer in history; rescanning all files", shortCommit(lastCommit)))
 			}
-			return set
+			return all()
 		}
+		add(changed)
 
 		if untracked, err := git.GetUntrackedFiles(); err == nil {
-			for _, f := range untracked {
-				normalized := filepath.ToSlash(f)
-				set[normalized] = struct{}{}
-			}
+			add(untracked)
 		}
 
 		if len(set) == 0 {
-			for _, f := range files {
-				set[f] = struct{}{}
-			}
+			return all()
 		}
 		return set
 	}
 
-	fallbackRoot := root
-	if cfg.RootPath != "." {
-		fallbackRoot = filepath.Join(root, cfg.RootPath)
-	}
-
-	fallbackFiles, err := git.GetModifiedFilesFallback(fallbackRoot, lastSync)
+	// File timestamps come from a coarser clock than time.Now, so a file written just after the last sync can
+	// carry an earlier modification time; look back a little further to catch it.
+	fallbackFiles, err := git.GetModifiedFilesFallback(root, lastSync.Add(-mtimeSlack))
 	if err != nil || len(fallbackFiles) == 0 {
-		for _, f := range files {
-			set[f] = struct{}{}
-		}
-		return set
+		return all()
 	}
 
 	for _, f := range fallbackFiles {
@@ -267,6 +454,45 @@ func determineUpdateSet(files []string, forceFull bool, lastSync time.Time, root
 	return set
 }
 
+// mtimeSlack covers the gap between file modification times and the recorded sync time.
+const mtimeSlack = 2 * time.Second
+
+// headCommit returns the commit to record as synced, or "" outside git or before the first commit.
+func headCommit() string {
+	head, err := git.HeadCommit()
+	if err != nil {
+		return ""
+	}
+	return head
+}
+
+// shortCommit abbreviates a commit SHA for messages.
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+// rootPrefix returns the root's slash-separated path relative to wd, which git-reported paths are rebased from.
+// It is not ok when the root lies outside wd.
+func rootPrefix(wd, root string) (string, bool) {
+	prefix, err := filepath.Rel(wd, root)
+	if err != nil || prefix == ".." || strings.HasPrefix(prefix, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(prefix), true
+}
+
+// relativeToRoot rebases a slash-separated path relative to the workspace onto the root at prefix.
+// Paths outside the root are reported as not ok.
+func relativeToRoot(p, prefix string) (string, bool) {
+	if prefix == "." || prefix == "" {
+		return p, true
+	}
+	return strings.CutPrefix(p, prefix+"/")
+}
+
 func printDetailedChanges(label string, items []string) {
 	if len(items) == 0 {
 		return
@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,7 +13,7 @@ import (
 
 func TestDetermineUpdateSetForceFull(t *testing.T) {
 	files := []string{"a.go", "b.go"}
-	set := determineUpdateSet(files, true, time.Now(), t.TempDir(), types.Config{})
+	set := determineUpdateSet(files, true, time.Now(), "", t.TempDir(), types.Config{})
 	if len(set) != 2 {
 		t.Fatalf("expected all files included")
 	}
@@ -26,7 +27,7 @@ func TestDetermineUpdateSetFallback(t *testing.T) {
 	}
 
 	files := []string{"file.go"}
-	set := determineUpdateSet(files, false, time.Now().Add(-time.Hour), dir, types.Config{RootPath: ""})
+	set := determineUpdateSet(files, false, time.Now().Add(-time.Hour), "", dir, types.Config{RootPath: ""})
 	if len(set) == 0 {
 		t.Fatalf("expected fallback to include file")
 	}
@@ -49,38 +50,102 @@ func TestPrintDetailedChanges(t *testing.T) {
 }
 
 func TestDetermineUpdateSetGit(t *testing.T) {
-	dir := t.TempDir()
-	if err := runGitCommand(dir, "init"); err != nil {
-		t.Skipf("git init failed: %v", err)
-	}
-	_ = runGitCommand(dir, "config", "user.email", "test@example.com")
-	_ = runGitCommand(dir, "config", "user.name", "Test User")
+	dir := initGitRepo(t)
+	writeTempFile(t, dir, "tracked.go", "package main\n")
+	writeTempFile(t, dir, "unchanged.go", "package main\n")
+	commitAll(t, dir, "init")
+	cleanup := changeDir(t, dir)
+	defer cleanup()
+	head := headCommit()
 
-	tracked := filepath.Join(dir, "tracked.go")
-	if err := os.WriteFile(tracked, []byte("package main\n"), 0o644); err != nil {
-		t.Fatalf("write tracked: %v", err)
-	}
-	if err := runGitCommand(dir, "add", "tracked.go"); err != nil {
-		t.Skipf("git add failed: %v", err)
+	writeTempFile(t, dir, "tracked.go", "package main\n// modified\n")
+	writeTempFile(t, dir, "untracked.go", "package main\n")
+
+	files := []string{"tracked.go", "unchanged.go", "untracked.go"}
+	set := determineUpdateSet(files, false, time.Now(), head, dir, types.Config{RootPath: "."})
+	if len(set) != 2 {
+		t.Fatalf("expected modified and untracked files included, got %v", set)
 	}
-	if err := runGitCommand(dir, "commit", "-m", "init"); err != nil {
-		t.Skipf("git commit failed: %v", err)
+	if _, ok := set["unchanged.go"]; ok {
+		t.Fatalf("expected unchanged file skipped, got %v", set)
 	}
+}
+
+func TestDetermineUpdateSetSeesCommittedChanges(t *testing.T) {
+	dir := initGitRepo(t)
+	writeTempFile(t, dir, "a.go", "package main\n")
+	writeTempFile(t, dir, "b.go", "package main\n")
+	commitAll(t, dir, "init")
+	cleanup := changeDir(t, dir)
+	defer cleanup()
+	synced := headCommit()
+
+	writeTempFile(t, dir, "a.go", "package main\n\nfunc A() {}\n")
+	commitAll(t, dir, "change a")
 
-	if err := os.WriteFile(tracked, []byte("package main\n// modified\n"), 0o644); err != nil {
-		t.Fatalf("modify tracked: %v", err)
+	files := []string{"a.go", "b.go"}
+	set := determineUpdateSet(files, false, time.Now(), synced, dir, types.Config{RootPath: "."})
+	if _, ok := set["a.go"]; !ok || len(set) != 1 {
+		t.Fatalf("expected only the committed change, got %v", set)
 	}
-	if err := os.WriteFile(filepath.Join(dir, "untracked.go"), []byte("package main\n"), 0o644); err != nil {
-		t.Fatalf("write untracked: %v", err)
+
+	var unknown map[string]struct{}
+	stdout := captureOutput(t, func() {
+		unknown = determineUpdateSet(files, false, time.Now(), "0123456789abcdef0123456789abcdef01234567", dir, types.Config{RootPath: "."})
+	})
+	if len(unknown) != 2 || !strings.Contains(stdout, "no longer in history") {
+		t.Fatalf("expected full rescan for an unknown commit, got %v:\n%s", unknown, stdout)
 	}
+}
 
-	files := []string{"tracked.go", "untracked.go"}
+func TestDetermineUpdateSetAfterBranchSwitch(t *testing.T) {
+	dir := initGitRepo(t)
+	writeTempFile(t, dir, "a.go", "package main\n")
+	writeTempFile(t, dir, "b.go", "package main\n")
+	commitAll(t, dir, "init")
+	if err := runGitCommand(dir, "checkout", "-q", "-b", "feature"); err != nil {
+		t.Skipf("git checkout failed: %v", err)
+	}
+	writeTempFile(t, dir, "a.go", "package main\n\nfunc A() {}\n")
+	commitAll(t, dir, "change a on feature")
 	cleanup := changeDir(t, dir)
 	defer cleanup()
+	synced := headCommit()
 
-	set := determineUpdateSet(files, false, time.Now(), dir, types.Config{RootPath: "."})
-	if len(set) != 2 {
-		t.Fatalf("expected both files included, got %v", set)
+	if err := runGitCommand(dir, "checkout", "-q", "-"); err != nil {
+		t.Skipf("git checkout failed: %v", err)
+	}
+
+	var set map[string]struct{}
+	stdout := captureOutput(t, func() {
+		set = determineUpdateSet([]string{"a.go", "b.go"}, false, time.Now(), synced, dir, types.Config{RootPath: "."})
+	})
+	if _, ok := set["a.go"]; !ok || len(set) != 1 {
+		t.Fatalf("expected only the file that differs from the synced commit, got %v", set)
+	}
+	if strings.Contains(stdout, "no longer in history") {
+		t.Fatalf("expected no warning for a commit on another branch:\n%s", stdout)
+	}
+}
+
+func initGitRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := runGitCommand(dir, "init"); err != nil {
+		t.Skipf("git init failed: %v", err)
+	}
+	_ = runGitCommand(dir, "config", "user.email", "test@example.com")
+	_ = runGitCommand(dir, "config", "user.name", "Test User")
+	return dir
+}
+
+func commitAll(t *testing.T, dir, message string) {
+	t.Helper()
+	if err := runGitCommand(dir, "add", "-A"); err != nil {
+		t.Skipf("git add failed: %v", err)
+	}
+	if err := runGitCommand(dir, "commit", "-m", message); err != nil {
+		t.Skipf("git commit failed: %v", err)
 	}
 }
 
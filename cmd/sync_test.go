@@ -0,0 +1,456 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dakshpareek/ctx/internal/config"
+	"github.com/dakshpareek/ctx/internal/fs"
+	"github.com/dakshpareek/ctx/internal/lock"
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+func TestSyncMarksSkeletonsFromOlderTemplatesStale(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "main.go", "package main\n")
+	writeTempFile(t, dir, "web/app.ts", "export const app = 1;\n")
+	_, _ = executeCommand(t, dir, "init")
+	_ = execAndCaptureStdout(t, dir, "ask", "--no-local", "--quiet")
+
+	response := "**Skeleton Path:** .ctx/skeletons/main.skeleton.go\n```\n**main**\n```\n\n" +
+		"**Skeleton Path:** .ctx/skeletons/web/app.skeleton.ts\n```\n**app**\n```\n"
+	writeTempFile(t, dir, "response.md", response)
+	_ = execAndCaptureStdout(t, dir, "apply", "response.md")
+
+	idx := loadIndex(t, dir)
+	entry := idx.Files["main.go"]
+	if entry.PromptVersion != config.DefaultSkeletonPromptVersion || entry.TemplateHash == "" {
+		t.Fatalf("expected prompt version and template hash to be recorded, got %+v", entry)
+	}
+
+	if stdout := execAndCaptureStdout(t, dir, "sync"); !strings.Contains(stdout, "No changes detected") {
+		t.Fatalf("expected unchanged template to keep skeletons current:\n%s", stdout)
+	}
+
+	writeTempFile(t, dir, ".ctx/prompts/ts.txt", "TypeScript sections\n")
+	stdout := execAndCaptureStdout(t, dir, "sync")
+	if !strings.Contains(stdout, "1 skeleton(s) from an older prompt template") {
+		t.Fatalf("expected template change to be reported:\n%s", stdout)
+	}
+	idx = loadIndex(t, dir)
+	if status := idx.Files["web/app.ts"].Status; status != types.StatusStale {
+		t.Fatalf("expected app.ts stale after template change, got %s", status)
+	}
+	if status := idx.Files["main.go"].Status; status != types.StatusCurrent {
+		t.Fatalf("expected main.go to stay current, got %s", status)
+	}
+
+	configPath := filepath.Join(dir, ".ctx", "config.json")
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	cfg.SkeletonPromptVersion = "3.0"
+	if err := fs.WriteJSON(configPath, cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	_ = execAndCaptureStdout(t, dir, "sync")
+	idx = loadIndex(t, dir)
+	if status := idx.Files["main.go"].Status; status != types.StatusStale {
+		t.Fatalf("expected main.go stale after version bump, got %s", status)
+	}
+	if idx.PromptVersion != "3.0" {
+		t.Fatalf("expected index prompt version 3.0, got %s", idx.PromptVersion)
+	}
+}
+
+func TestMutatingCommandsRespectWorkspaceLock(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "main.go", "package main\n")
+	_, _ = executeCommand(t, dir, "init")
+
+	original := lockTimeout
+	lockTimeout = 0
+	t.Cleanup(func() { lockTimeout = original })
+
+	held, err := lock.Acquire(filepath.Join(dir, ".ctx", lock.FileName), "test", 0)
+	if err != nil {
+		t.Fatalf("acquire lock: %v", err)
+	}
+
+	if _, _, err := executeCommandAllowError(t, dir, "sync"); err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Fatalf("expected sync to fail while locked, got %v", err)
+	}
+	if _, _, err := executeCommandAllowError(t, dir, "status"); err != nil {
+		t.Fatalf("expected read-only status to ignore the lock: %v", err)
+	}
+
+	held.Release()
+	if _, _, err := executeCommandAllowError(t, dir, "sync"); err != nil {
+		t.Fatalf("expected sync to succeed after release: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".ctx", lock.FileName)); !os.IsNotExist(err) {
+		t.Fatalf("expected lock released after sync, got %v", err)
+	}
+}
+
+func TestSyncWithJobsMatchesSequential(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 20; i++ {
+		writeTempFile(t, dir, fmt.Sprintf("pkg%d/file%d.go", i%4, i), fmt.Sprintf("package pkg\n\nconst V%d = %d\n", i, i))
+	}
+	_, _ = executeCommand(t, dir, "init", "--jobs", "4")
+
+	idx := loadIndex(t, dir)
+	if len(idx.Files) != 20 {
+		t.Fatalf("expected 20 tracked files, got %d", len(idx.Files))
+	}
+
+	writeTempFile(t, dir, "pkg1/file5.go", "package pkg\n\nconst V5 = 500\n")
+	writeTempFile(t, dir, "pkg3/file19.go", "package pkg\n\nconst V19 = 1900\n")
+	if err := os.Remove(filepath.Join(dir, "pkg0", "file0.go")); err != nil {
+		t.Fatalf("remove file: %v", err)
+	}
+
+	stdout := execAndCaptureStdout(t, dir, "sync", "--full", "--jobs", "3", "--verbose")
+	if !strings.Contains(stdout, "Modified:\n  - pkg1/file5.go\n  - pkg3/file19.go\n") {
+		t.Fatalf("expected modified files listed in order:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, "Deleted:\n  - pkg0/file0.go\n") {
+		t.Fatalf("expected deleted file listed:\n%s", stdout)
+	}
+
+	idx = loadIndex(t, dir)
+	if len(idx.Files) != 19 {
+		t.Fatalf("expected 19 tracked files, got %d", len(idx.Files))
+	}
+	if status := idx.Files["pkg3/file19.go"].Status; status != types.StatusStale {
+		t.Fatalf("expected modified file marked stale, got %s", status)
+	}
+	if status := idx.Files["pkg2/file6.go"].Status; status != types.StatusMissing {
+		t.Fatalf("expected unchanged file to keep its status, got %s", status)
+	}
+}
+
+func TestSyncKeepsCosmeticEditsCurrentWithSemanticHash(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "main.go", "package main\n\n// Run starts the app.\nfunc Run() {}\n")
+	writeTempFile(t, dir, "util.go", "package main\n\nfunc Helper() int { return 1 }\n")
+	_, _ = executeCommand(t, dir, "init")
+
+	configPath := filepath.Join(dir, ".ctx", "config.json")
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	cfg.SemanticHash = true
+	if err := fs.WriteJSON(configPath, cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	_ = execAndCaptureStdout(t, dir, "ask", "--no-local", "--quiet")
+	response := "**Skeleton Path:** .ctx/skeletons/main.skeleton.go\n```\n**main**\n```\n\n" +
+		"**Skeleton Path:** .ctx/skeletons/util.skeleton.go\n```\n**util**\n```\n"
+	writeTempFile(t, dir, "response.md", response)
+	_ = execAndCaptureStdout(t, dir, "apply", "response.md")
+
+	writeTempFile(t, dir, "main.go", "package main\n\n// Run starts the application.\nfunc Run() {\n}\n")
+	writeTempFile(t, dir, "util.go", "package main\n\nfunc Helper(n int) int { return n }\n")
+
+	stdout := execAndCaptureStdout(t, dir, "sync", "--full", "--verbose")
+	if !strings.Contains(stdout, "1 cosmetic change(s) (kept current)") || !strings.Contains(stdout, "Cosmetic:\n  - main.go\n") {
+		t.Fatalf("expected cosmetic change reported:\n%s", stdout)
+	}
+
+	idx := loadIndex(t, dir)
+	if status := idx.Files["main.go"].Status; status != types.StatusCurrent {
+		t.Fatalf("expected comment-only edit to stay current, got %s", status)
+	}
+	if status := idx.Files["util.go"].Status; status != types.StatusStale {
+		t.Fatalf("expected signature change to be stale, got %s", status)
+	}
+
+	if stdout := execAndCaptureStdout(t, dir, "validate"); !strings.Contains(stdout, "No issues found.") {
+		t.Fatalf("expected validate to accept the cosmetic edit:\n%s", stdout)
+	}
+}
+
+func TestSyncAppliesConfiguredFileTypeRules(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "api/user_endpoint.go", "package api\n\n// Route: GET /users\nfunc Users() {}\n")
+	writeTempFile(t, dir, "api/order.service.go", "package api\n")
+	_, _ = executeCommand(t, dir, "init")
+
+	idx := loadIndex(t, dir)
+	if got := idx.Files["api/order.service.go"].Type; got != "service" {
+		t.Fatalf("expected default rules to classify service, got %q", got)
+	}
+	if got := idx.Files["api/user_endpoint.go"].Type; got != "" {
+		t.Fatalf("expected no type before custom rules, got %q", got)
+	}
+
+	configPath := filepath.Join(dir, ".ctx", "config.json")
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	cfg.FileTypes = append([]types.FileTypeRule{
+		{Type: "controller", Paths: []string{"api/**"}, Content: `// Route: `},
+	}, cfg.FileTypes...)
+	if err := fs.WriteJSON(configPath, cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	_ = execAndCaptureStdout(t, dir, "sync", "--full")
+	idx = loadIndex(t, dir)
+	if got := idx.Files["api/user_endpoint.go"].Type; got != "controller" {
+		t.Fatalf("expected content rule to classify controller, got %q", got)
+	}
+	if got := idx.Files["api/order.service.go"].Type; got != "service" {
+		t.Fatalf("expected service to keep its type, got %q", got)
+	}
+}
+
+func TestRootPathIndexesOnlySourceDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "src/main.go", "package main\n\nfunc Run() error { return nil }\n")
+	writeTempFile(t, dir, "src/api/types_gen.go", "package api\n")
+	writeTempFile(t, dir, "src/api/users.go", "package api\n\nfunc Users() {}\n")
+	writeTempFile(t, dir, "scripts/release.go", "package scripts\n")
+	writeTempFile(t, dir, ".gitignore", "*_gen.go\n")
+	writeTempFile(t, dir, ".git/info/exclude", "")
+	_, _ = executeCommand(t, dir, "init")
+
+	configPath := filepath.Join(dir, ".ctx", "config.json")
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	cfg.RootPath = "src"
+	cfg.IncludedExtensions = []string{".go"}
+	if err := fs.WriteJSON(configPath, cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	_ = execAndCaptureStdout(t, dir, "sync", "--full")
+	idx := loadIndex(t, dir)
+	if len(idx.Files) != 2 || idx.Files["main.go"].Path == "" || idx.Files["api/users.go"].Path == "" {
+		t.Fatalf("expected paths relative to src without ignored files, got %v", idx.Files)
+	}
+
+	_ = execAndCaptureStdout(t, dir, "generate", "--quiet")
+	if _, err := os.Stat(filepath.Join(dir, ".ctx", "skeletons", "main.skeleton.go")); err != nil {
+		t.Fatalf("expected skeleton under the workspace .ctx/: %v", err)
+	}
+	if stdout := execAndCaptureStdout(t, dir, "validate"); !strings.Contains(stdout, "No issues found.") {
+		t.Fatalf("expected validate to read sources from the root:\n%s", stdout)
+	}
+
+	writeTempFile(t, dir, "src/main.go", "package main\n\nfunc Run() error { return nil }\n\nfunc Stop() {}\n")
+	_ = execAndCaptureStdout(t, dir, "sync")
+	if status := loadIndex(t, dir).Files["main.go"].Status; status != types.StatusStale {
+		t.Fatalf("expected edited file under the root to be stale, got %s", status)
+	}
+
+	_ = execAndCaptureStdout(t, dir, "generate", "--no-local", "--diff", "--quiet")
+	prompt, err := os.ReadFile(filepath.Join(dir, ".ctx", "prompt.md"))
+	if err != nil {
+		t.Fatalf("read prompt: %v", err)
+	}
+	if !strings.Contains(string(prompt), "+func Stop() {}") {
+		t.Fatalf("expected prompt diff against the source under the root:\n%s", prompt)
+	}
+}
+
+func TestSyncCarriesSkeletonsAcrossRenames(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "api/user.go", "package api\n\n// User is an account.\ntype User struct{}\n")
+	writeTempFile(t, dir, "api/order.go", "package api\n\n// Order is a purchase.\ntype Order struct{}\n")
+	_, _ = executeCommand(t, dir, "init")
+	_ = execAndCaptureStdout(t, dir, "generate", "--quiet")
+
+	if err := os.MkdirAll(filepath.Join(dir, "users"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.Rename(filepath.Join(dir, "api", "user.go"), filepath.Join(dir, "users", "user.go")); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+
+	stdout := execAndCaptureStdout(t, dir, "sync", "--full", "--verbose")
+	if !strings.Contains(stdout, "1 renamed (skeletons moved)") || !strings.Contains(stdout, "api/user.go → users/user.go") {
+		t.Fatalf("expected rename reported:\n%s", stdout)
+	}
+
+	idx := loadIndex(t, dir)
+	entry, ok := idx.Files["users/user.go"]
+	if !ok || entry.Status != types.StatusCurrent {
+		t.Fatalf("expected moved file to stay current, got %+v", entry)
+	}
+	if _, ok := idx.Files["api/user.go"]; ok {
+		t.Fatalf("expected old path dropped from index")
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".ctx", "skeletons", "api", "user.skeleton.go")); !os.IsNotExist(err) {
+		t.Fatalf("expected old skeleton moved away, got %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(entry.SkeletonPath)))
+	if err != nil {
+		t.Fatalf("read moved skeleton: %v", err)
+	}
+	if !strings.Contains(string(data), "- File: `users/user.go:1`") {
+		t.Fatalf("expected File line rewritten:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".ctx", "snapshots", "users", "user.go")); err != nil {
+		t.Fatalf("expected snapshot moved: %v", err)
+	}
+
+	if stdout := execAndCaptureStdout(t, dir, "validate"); !strings.Contains(stdout, "No issues found.") {
+		t.Fatalf("expected moved skeleton to validate:\n%s", stdout)
+	}
+}
+
+func TestSyncRenameKeepsExistingSkeletonAtDestination(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "api/user.go", "package api\n\n// User is an account.\ntype User struct{}\n")
+	_, _ = executeCommand(t, dir, "init")
+	_ = execAndCaptureStdout(t, dir, "generate", "--quiet")
+
+	leftover := filepath.Join(".ctx", "skeletons", "users", "user.skeleton.go")
+	writeTempFile(t, dir, leftover, "hand-written notes\n")
+	if err := os.MkdirAll(filepath.Join(dir, "users"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.Rename(filepath.Join(dir, "api", "user.go"), filepath.Join(dir, "users", "user.go")); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+
+	stdout := execAndCaptureStdout(t, dir, "sync", "--full")
+	if !strings.Contains(stdout, "already exists") {
+		t.Fatalf("expected a warning about the existing skeleton:\n%s", stdout)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, leftover)); string(data) != "hand-written notes\n" {
+		t.Fatalf("expected the existing skeleton to be left alone, got %q", data)
+	}
+	entry := loadIndex(t, dir).Files["users/user.go"]
+	if entry.SkeletonPath != ".ctx/skeletons/api/user.skeleton.go" {
+		t.Fatalf("expected the entry to keep its skeleton, got %q", entry.SkeletonPath)
+	}
+	if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(entry.SkeletonPath))); err != nil {
+		t.Fatalf("expected the original skeleton to stay in place: %v", err)
+	}
+}
+
+func TestSyncFollowsGitRenamesWithEdits(t *testing.T) {
+	dir := initGitRepo(t)
+	body := "package api\n\n// User is an account.\ntype User struct {\n\tID    int\n\tName  string\n\tEmail string\n}\n"
+	writeTempFile(t, dir, "api/user.go", body)
+	commitAll(t, dir, "init")
+	_, _ = executeCommand(t, dir, "init")
+	_ = execAndCaptureStdout(t, dir, "generate", "--quiet")
+
+	if err := runGitCommand(dir, "mv", "api/user.go", "api/account.go"); err != nil {
+		t.Skipf("git mv failed: %v", err)
+	}
+	writeTempFile(t, dir, "api/account.go", strings.Replace(body, "User", "Account", 2))
+	if err := runGitCommand(dir, "add", "-A"); err != nil {
+		t.Skipf("git add failed: %v", err)
+	}
+
+	stdout := execAndCaptureStdout(t, dir, "sync")
+	if !strings.Contains(stdout, "1 renamed (skeletons moved)") {
+		t.Fatalf("expected git rename reported:\n%s", stdout)
+	}
+
+	entry := loadIndex(t, dir).Files["api/account.go"]
+	if entry.Status != types.StatusStale {
+		t.Fatalf("expected edited rename to be stale, got %+v", entry)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".ctx", "skeletons", "api", "account.skeleton.go")); err != nil {
+		t.Fatalf("expected skeleton moved to the new path: %v", err)
+	}
+}
+
+func TestRevReportsCoverageWithoutCheckout(t *testing.T) {
+	dir := initGitRepo(t)
+	writeTempFile(t, dir, "api/user.go", "package api\n\n// User is an account.\ntype User struct{}\n")
+	writeTempFile(t, dir, "api/order.go", "package api\n\n// Order is a purchase.\ntype Order struct{}\n")
+	commitAll(t, dir, "v1")
+	if err := runGitCommand(dir, "branch", "release"); err != nil {
+		t.Skipf("git branch failed: %v", err)
+	}
+
+	writeTempFile(t, dir, "api/user.go", "package api\n\n// User is an account.\ntype User struct{ Name string }\n")
+	writeTempFile(t, dir, "api/cart.go", "package api\n\n// Cart holds orders.\ntype Cart struct{}\n")
+	commitAll(t, dir, "v2")
+	_, _ = executeCommand(t, dir, "init")
+	_ = execAndCaptureStdout(t, dir, "generate", "--quiet")
+
+	var status types.Index
+	if err := json.Unmarshal([]byte(execAndCaptureStdout(t, dir, "status", "--rev", "release", "--json")), &status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if status.Stats.TotalFiles != 2 || status.Stats.Current != 1 || status.Stats.Stale != 1 {
+		t.Fatalf("expected release to have one current and one stale skeleton, got %+v", status.Stats)
+	}
+	if status.Files["api/user.go"].Status != types.StatusStale || status.LastSyncedCommit == "" {
+		t.Fatalf("expected the edited file stale at the release commit, got %+v", status.Files["api/user.go"])
+	}
+
+	export := execAndCaptureStdout(t, dir, "export", "--rev", "release")
+	if !strings.Contains(export, "### api/order.go") || strings.Contains(export, "### api/user.go") || strings.Contains(export, "### api/cart.go") {
+		t.Fatalf("expected only the skeleton current at release exported:\n%s", export)
+	}
+
+	stdout := execAndCaptureStdout(t, dir, "sync", "--rev", "release")
+	if !strings.Contains(stdout, "1 modified") || !strings.Contains(stdout, "1 deleted") || !strings.Contains(stdout, "Index not saved") {
+		t.Fatalf("expected sync to report the differences from release:\n%s", stdout)
+	}
+	idx := loadIndex(t, dir)
+	if len(idx.Files) != 3 || idx.Files["api/user.go"].Status != types.StatusCurrent {
+		t.Fatalf("expected --rev to leave the index alone, got %+v", idx.Files)
+	}
+
+	if _, _, err := executeCommandAllowError(t, dir, "status", "--rev", "no-such-branch"); err == nil || !strings.Contains(err.Error(), "unknown revision") {
+		t.Fatalf("expected an unknown revision error, got %v", err)
+	}
+}
+
+func TestSyncDoesNotTrustSkeletonsAppliedAfterAnEdit(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "a.go", "package main\n\nfunc Run() {}\n")
+	_, _ = executeCommand(t, dir, "init")
+
+	configPath := filepath.Join(dir, ".ctx", "config.json")
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	cfg.SemanticHash = true
+	if err := fs.WriteJSON(configPath, cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	_ = execAndCaptureStdout(t, dir, "generate", "--no-local", "--quiet")
+	writeTempFile(t, dir, "a.go", "package main\n\nfunc Run() {}\n\nfunc NewExported() {}\n")
+	writeTempFile(t, dir, "response.md", "**Skeleton Path:** .ctx/skeletons/a.skeleton.go\n```\n**a**\n```\n")
+	stdout := execAndCaptureStdout(t, dir, "apply", "response.md")
+	if !strings.Contains(stdout, "a.go changed after its prompt was built") {
+		t.Fatalf("expected apply to warn about the edited source:\n%s", stdout)
+	}
+
+	if _, _, err := executeCommandAllowError(t, dir, "validate", "--strict"); err == nil {
+		t.Fatalf("expected validate --strict to report the edited source")
+	}
+
+	stdout = execAndCaptureStdout(t, dir, "sync")
+	if strings.Contains(stdout, "cosmetic") {
+		t.Fatalf("expected no cosmetic change for an added export:\n%s", stdout)
+	}
+	if status := loadIndex(t, dir).Files["a.go"].Status; status != types.StatusStale {
+		t.Fatalf("expected a.go stale, got %s", status)
+	}
+}
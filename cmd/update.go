@@ -10,7 +10,14 @@ import (
 	"github.com/dakshpareek/ctx/internal/types"
 )
 
+type updateOptions struct {
+	paranoid bool
+	jobs     int
+}
+
 func newUpdateCmd() *cobra.Command {
+	opts := updateOptions{}
+
 	cmd := &cobra.Command{
 		Use:   "update",
 		Short: "Mark skeletons as current after you save AI updates",
@@ -21,14 +28,19 @@ ctx update will:
   2. Mark matching entries as current
   3. Highlight remaining work that still needs attention`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runUpdate()
+			return withWorkspaceLock(cmd.CommandPath(), func() error {
+				return runUpdate(opts)
+			})
 		},
 	}
 
+	cmd.Flags().BoolVar(&opts.paranoid, "paranoid", false, "Rehash every file instead of trusting unchanged size and modification time")
+	cmd.Flags().IntVar(&opts.jobs, "jobs", 0, "Number of files to hash in parallel (default: number of CPUs)")
+
 	return cmd
 }
 
-func runUpdate() error {
+func runUpdate(opts updateOptions) error {
 	_, indexPath, err := ensureWorkspace(true)
 	if err != nil {
 		return err
@@ -39,7 +51,7 @@ func runUpdate() error {
 		return err
 	}
 
-	if err := runValidate(validateOptions{fix: true}); err != nil {
+	if err := runValidate(validateOptions{fix: true, paranoid: opts.paranoid, jobs: opts.jobs}); err != nil {
 		return err
 	}
 
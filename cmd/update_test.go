@@ -12,7 +12,7 @@ func TestUpdateMarksFilesCurrentAndShowsSummary(t *testing.T) {
 	writeTempFile(t, dir, "main.go", "package main\n")
 	_, _ = executeCommand(t, dir, "init")
 
-	_, _ = executeCommand(t, dir, "ask", "--quiet")
+	_, _ = executeCommand(t, dir, "ask", "--no-local", "--quiet")
 
 	idx := loadIndex(t, dir)
 	entry, ok := idx.Files["main.go"]
@@ -40,7 +40,7 @@ func TestUpdateHandlesMissingSkeletonsGracefully(t *testing.T) {
 	dir := t.TempDir()
 	writeTempFile(t, dir, "main.go", "package main\n")
 	_, _ = executeCommand(t, dir, "init")
-	_, _ = executeCommand(t, dir, "ask", "--quiet")
+	_, _ = executeCommand(t, dir, "ask", "--no-local", "--quiet")
 
 	stdout := execAndCaptureStdout(t, dir, "update")
 	if !strings.Contains(stdout, "Remaining work detected") {
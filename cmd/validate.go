@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,17 +9,23 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/dakshpareek/ctx/internal/config"
 	"github.com/dakshpareek/ctx/internal/display"
 	"github.com/dakshpareek/ctx/internal/fs"
+	"github.com/dakshpareek/ctx/internal/git"
 	"github.com/dakshpareek/ctx/internal/hash"
 	"github.com/dakshpareek/ctx/internal/index"
+	"github.com/dakshpareek/ctx/internal/scanner"
 	"github.com/dakshpareek/ctx/internal/skeleton"
 	"github.com/dakshpareek/ctx/internal/types"
 )
 
 type validateOptions struct {
-	fix    bool
-	strict bool
+	fix      bool
+	strict   bool
+	paranoid bool
+	staged   bool
+	jobs     int
 }
 
 type validationIssue struct {
@@ -35,12 +42,20 @@ func newValidateCmd() *cobra.Command {
 		Long:  advancedDescription,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			printAdvancedNotice("ctx update")
-			return runValidate(opts)
+			if !opts.fix {
+				return runValidate(opts)
+			}
+			return withWorkspaceLock(cmd.CommandPath(), func() error {
+				return runValidate(opts)
+			})
 		},
 	}
 
 	cmd.Flags().BoolVar(&opts.fix, "fix", false, "automatically update index entries for detected issues")
 	cmd.Flags().BoolVar(&opts.strict, "strict", false, "exit with error if issues are found")
+	cmd.Flags().BoolVar(&opts.paranoid, "paranoid", false, "rehash every file instead of trusting unchanged size and modification time")
+	cmd.Flags().IntVar(&opts.jobs, "jobs", 0, "number of files to hash in parallel (default: number of CPUs)")
+	cmd.Flags().BoolVar(&opts.staged, "staged", false, "check only files staged in git, using their staged content")
 
 	return cmd
 }
@@ -66,14 +81,64 @@ func runValidate(opts validateOptions) error {
 		return &types.Error{Code: types.ExitCodeData, Err: err}
 	}
 
-	fmt.Println("Validating code context...")
+	cfg, err := config.LoadConfig(filepath.Join(ctxDir, configFileName))
+	if err != nil {
+		return &types.Error{Code: types.ExitCodeData, Err: err}
+	}
+
+	if opts.staged && opts.fix {
+		return &types.Error{Code: types.ExitCodeUserError, Err: fmt.Errorf("--fix cannot be combined with --staged")}
+	}
+
+	root := sourceRoot(wd, *cfg)
+	cache := openHashCache(ctxDir, opts.paranoid)
+	defer saveHashCache(cache)
+
+	var (
+		paths     []string
+		staged    map[string][]byte
+		unindexed []string
+	)
+	if opts.staged {
+		paths, staged, unindexed, err = stagedSources(wd, root, *cfg, idx, cache, opts.jobs)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Validating %d staged file(s)...\n", len(paths)+len(unindexed))
+	} else {
+		paths = make([]string, 0, len(idx.Files))
+		for path := range idx.Files {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		fmt.Println("Validating code context...")
+	}
 	fmt.Println()
 
-	paths := make([]string, 0, len(idx.Files))
-	for path := range idx.Files {
-		paths = append(paths, path)
+	skeletonPaths := make([]string, len(paths))
+	for i, path := range paths {
+		skeletonPaths[i] = idx.Files[path].SkeletonPath
+		if skeletonPaths[i] == "" {
+			skeletonPaths[i] = skeleton.PathForSource(path)
+		}
+	}
+
+	var sourceStates []fileState
+	if opts.staged {
+		sourceStates = make([]fileState, len(paths))
+		for i, path := range paths {
+			sourceStates[i] = fileState{hash: hash.HashContent(staged[path])}
+		}
+	} else {
+		sourceStates, err = hashFiles(root, paths, cache, opts.jobs)
+		if err != nil {
+			return err
+		}
+	}
+	skeletonStates, err := hashFiles(wd, skeletonPaths, cache, opts.jobs)
+	if err != nil {
+		return err
 	}
-	sort.Strings(paths)
 
 	var (
 		issues        []validationIssue
@@ -85,39 +150,45 @@ func runValidate(opts validateOptions) error {
 		modified      bool
 	)
 
-	for _, path := range paths {
+	for i, path := range paths {
 		entry := idx.Files[path]
-		sourcePath := filepath.Join(wd, filepath.FromSlash(path))
 
-		info, err := os.Stat(sourcePath)
-		if err != nil {
-			if os.IsNotExist(err) {
-				message := fmt.Sprintf("%s: source file missing", path)
-				if opts.fix {
-					removePaths = append(removePaths, path)
-					removedCount++
-					modified = true
-					issues = append(issues, validationIssue{
-						message:  message + " (removed from index)",
-						resolved: true,
-					})
-				} else {
-					issues = append(issues, validationIssue{
-						message:  message,
-						resolved: false,
-					})
-				}
-				continue
+		if sourceStates[i].missing {
+			message := fmt.Sprintf("%s: source file missing", path)
+			if opts.fix {
+				removePaths = append(removePaths, path)
+				removedCount++
+				modified = true
+				issues = append(issues, validationIssue{
+					message:  message + " (removed from index)",
+					resolved: true,
+				})
+			} else {
+				issues = append(issues, validationIssue{
+					message:  message,
+					resolved: false,
+				})
 			}
-			return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("stat %s: %w", path, err)}
-		}
-
-		currentHash, err := hash.HashFile(sourcePath)
-		if err != nil {
-			return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("hash %s: %w", path, err)}
+			continue
 		}
+		info, currentHash := sourceStates[i].info, sourceStates[i].hash
 
 		hashChanged := currentHash != entry.Hash
+		cosmetic := false
+		if hashChanged && opts.staged {
+			cosmetic = cosmeticContent(entry, *cfg, staged[path])
+		} else if hashChanged {
+			cosmetic = cosmeticChange(os.DirFS(root), entry, *cfg)
+		}
+		if cosmetic {
+			hashChanged = false
+			if opts.fix {
+				entry.Hash = currentHash
+				entry.LastModified = info.ModTime().UTC()
+				entry.Size = info.Size()
+				modified = true
+			}
+		}
 		if hashChanged {
 			message := fmt.Sprintf("%s: source hash mismatch", path)
 			if opts.fix {
@@ -139,15 +210,9 @@ func runValidate(opts validateOptions) error {
 			}
 		}
 
-		skelPath := entry.SkeletonPath
-		if skelPath == "" {
-			skelPath = skeleton.PathForSource(path)
-		}
-		entry.SkeletonPath = skelPath
-
-		fullSkeletonPath := filepath.Join(wd, filepath.FromSlash(skelPath))
+		entry.SkeletonPath = skeletonPaths[i]
 
-		skeletonExists := fs.Exists(fullSkeletonPath)
+		skeletonExists := !skeletonStates[i].missing
 		skeletonHashChanged := false
 
 		if !skeletonExists {
@@ -168,10 +233,7 @@ func runValidate(opts validateOptions) error {
 				})
 			}
 		} else {
-			skeletonHash, err := hash.HashFile(fullSkeletonPath)
-			if err != nil {
-				return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("hash skeleton %s: %w", skelPath, err)}
-			}
+			skeletonHash := skeletonStates[i].hash
 
 			if entry.SkeletonHash == "" {
 				if opts.fix {
@@ -199,18 +261,38 @@ func runValidate(opts validateOptions) error {
 			if opts.fix && !hashChanged && entry.SkeletonHash != "" &&
 				entry.Status != types.StatusMissing {
 				if entry.Status == types.StatusPendingGeneration || skeletonHashChanged {
+					var err error
 					entry.Status = types.StatusCurrent
+					entry, err = recordSourceSnapshot(wd, root, entry)
+					if err != nil {
+						return err
+					}
 					currentMarked++
 					modified = true
 				}
 			}
 		}
 
+		// A pre-commit gate needs more than consistency: the skeleton must describe the staged source.
+		if opts.staged && !hashChanged && skeletonExists && entry.Status != types.StatusCurrent {
+			issues = append(issues, validationIssue{
+				message:  fmt.Sprintf("%s: skeleton is %s", path, entry.Status),
+				resolved: false,
+			})
+		}
+
 		if opts.fix {
 			idx.Files[path] = entry
 		}
 	}
 
+	for _, path := range unindexed {
+		issues = append(issues, validationIssue{
+			message:  fmt.Sprintf("%s: not indexed; run 'ctx sync' and generate its skeleton", path),
+			resolved: false,
+		})
+	}
+
 	for _, path := range removePaths {
 		delete(idx.Files, path)
 	}
@@ -262,3 +344,65 @@ func runValidate(opts validateOptions) error {
 
 	return nil
 }
+
+// stagedSources returns the tracked paths staged in git, relative to the root, with their staged content.
+// Staged files the index does not track yet are returned separately when a scan would index them, so a new
+// source file cannot be committed without a skeleton; files the scan filters or classifies away are ignored.
+func stagedSources(wd, root string, cfg types.Config, idx *types.Index, cache *hash.Cache, jobs int) ([]string, map[string][]byte, []string, error) {
+	prefix, ok := rootPrefix(wd, root)
+	if !ok {
+		return nil, nil, nil, &types.Error{Code: types.ExitCodeUserError, Err: fmt.Errorf("--staged needs rootPath inside the workspace")}
+	}
+
+	files, err := git.GetStagedFiles()
+	if err != nil {
+		if errors.Is(err, git.ErrNotGit) {
+			return nil, nil, nil, &types.Error{Code: types.ExitCodeUserError, Err: fmt.Errorf("--staged requires a git repository")}
+		}
+		return nil, nil, nil, &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+	}
+
+	var (
+		paths     []string
+		untracked []string
+	)
+	contents := make(map[string][]byte)
+	for _, file := range files {
+		path, ok := relativeToRoot(filepath.ToSlash(file), prefix)
+		if !ok {
+			continue
+		}
+		if _, tracked := idx.Files[path]; !tracked {
+			untracked = append(untracked, path)
+			continue
+		}
+		content, err := git.StagedContent(file)
+		if err != nil {
+			return nil, nil, nil, &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+		}
+		paths = append(paths, path)
+		contents[path] = content
+	}
+	sort.Strings(paths)
+
+	var unindexed []string
+	if len(untracked) > 0 {
+		scanCfg := cfg
+		scanCfg.RootPath = root
+		scanned, err := scanner.ScanCached(scanCfg, jobs, cache)
+		if err != nil {
+			return nil, nil, nil, &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("scan files: %w", err)}
+		}
+		indexable := make(map[string]bool, len(scanned.Files))
+		for _, file := range scanned.Files {
+			indexable[file] = true
+		}
+		for _, path := range untracked {
+			if indexable[path] {
+				unindexed = append(unindexed, path)
+			}
+		}
+		sort.Strings(unindexed)
+	}
+	return paths, contents, unindexed, nil
+}
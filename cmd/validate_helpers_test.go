@@ -1,6 +1,12 @@
 package cmd
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestValidateStrictFailure(t *testing.T) {
 	dir := t.TempDir()
@@ -16,3 +22,41 @@ func TestValidateStrictFailure(t *testing.T) {
 		t.Fatalf("expected error in strict mode when issues exist")
 	}
 }
+
+func TestValidateParanoidRehashesUnchangedStat(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "main.go")
+	writeTempFile(t, dir, "main.go", "package main\n")
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(source, old, old); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	_, _ = executeCommand(t, dir, "init")
+
+	// Rewrite with the same size and modification time: only a rehash can notice.
+	writeTempFile(t, dir, "main.go", "package demo\n")
+	if err := os.Chtimes(source, old, old); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	cleanup := changeDir(t, dir)
+	defer cleanup()
+
+	output := captureOutput(t, func() {
+		if err := runValidate(validateOptions{}); err != nil {
+			t.Fatalf("runValidate: %v", err)
+		}
+	})
+	if strings.Contains(output, "source hash mismatch") {
+		t.Fatalf("expected cached hash to be trusted:\n%s", output)
+	}
+
+	output = captureOutput(t, func() {
+		if err := runValidate(validateOptions{paranoid: true}); err != nil {
+			t.Fatalf("runValidate: %v", err)
+		}
+	})
+	if !strings.Contains(output, "main.go: source hash mismatch") {
+		t.Fatalf("expected paranoid validate to detect the change:\n%s", output)
+	}
+}
@@ -1,13 +1,18 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	stdfs "io/fs"
 	"os"
 	"path/filepath"
 
 	"github.com/dakshpareek/ctx/internal/display"
 	"github.com/dakshpareek/ctx/internal/fs"
+	"github.com/dakshpareek/ctx/internal/hash"
+	"github.com/dakshpareek/ctx/internal/lock"
 	"github.com/dakshpareek/ctx/internal/types"
+	"github.com/dakshpareek/ctx/internal/workers"
 )
 
 func ensureWorkspace(requireIndex bool) (string, string, error) {
@@ -30,3 +35,102 @@ func ensureWorkspace(requireIndex bool) (string, string, error) {
 
 	return ctxDir, indexPath, nil
 }
+
+// lockTimeout bounds how long a command waits for another ctx process to finish.
+var lockTimeout = lock.DefaultTimeout
+
+// withWorkspaceLock runs fn while holding the .ctx lock file so concurrent ctx processes cannot interleave writes.
+// Without a workspace, fn runs unlocked and reports the missing .ctx/ itself.
+func withWorkspaceLock(command string, fn func() error) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("determine working directory: %w", err)}
+	}
+
+	ctxDir := filepath.Join(wd, ctxDirName)
+	if !fs.Exists(ctxDir) {
+		return fn()
+	}
+
+	held, err := lock.Acquire(filepath.Join(ctxDir, lock.FileName), command, lockTimeout)
+	if err != nil {
+		return &types.Error{Code: types.ExitCodeFileSystem, Err: err}
+	}
+	defer held.Release()
+
+	return fn()
+}
+
+// openHashCache loads the stat cache that lets unchanged files skip rehashing.
+func openHashCache(ctxDir string, paranoid bool) *hash.Cache {
+	cache := hash.LoadCache(filepath.Join(ctxDir, hash.CacheFileName))
+	cache.Paranoid = paranoid
+	return cache
+}
+
+// saveHashCache persists the stat cache; a failure only costs rehashing next time, so it is reported as a warning.
+func saveHashCache(cache *hash.Cache) {
+	if err := cache.Save(); err != nil {
+		fmt.Println(display.Warning("Could not save hash cache: %v", err))
+	}
+}
+
+// fileState is the stat and content hash of one file, or missing when it no longer exists.
+type fileState struct {
+	info    os.FileInfo
+	hash    string
+	missing bool
+}
+
+// hashFiles stats and hashes paths relative to root on up to jobs workers and returns the states in input order.
+// When several files fail, the error for the earliest path is returned.
+func hashFiles(root string, paths []string, cache *hash.Cache, jobs int) ([]fileState, error) {
+	return workers.Map(paths, jobs, func(path string) (fileState, error) {
+		fullPath := filepath.Join(root, filepath.FromSlash(path))
+		info, err := os.Stat(fullPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fileState{missing: true}, nil
+			}
+			return fileState{}, &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("stat %s: %w", path, err)}
+		}
+
+		value, err := cache.HashFile(path, fullPath, info)
+		if err != nil {
+			return fileState{}, &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("hash %s: %w", path, err)}
+		}
+		return fileState{info: info, hash: value}, nil
+	})
+}
+
+// hashSource is hashFiles for paths in source, such as a git revision, where there is no stat cache to consult.
+func hashSource(source stdfs.FS, paths []string, jobs int) ([]fileState, error) {
+	return workers.Map(paths, jobs, func(path string) (fileState, error) {
+		info, err := stdfs.Stat(source, path)
+		if err != nil {
+			if errors.Is(err, stdfs.ErrNotExist) {
+				return fileState{missing: true}, nil
+			}
+			return fileState{}, &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("stat %s: %w", path, err)}
+		}
+
+		value, err := hash.HashFS(source, path)
+		if err != nil {
+			return fileState{}, &types.Error{Code: types.ExitCodeFileSystem, Err: fmt.Errorf("hash %s: %w", path, err)}
+		}
+		return fileState{info: info, hash: value}, nil
+	})
+}
+
+// sourceRoot resolves Config.RootPath against the workspace directory. Index paths are relative to it,
+// while skeletons, snapshots and the rest of .ctx/ stay under the workspace directory.
+func sourceRoot(wd string, cfg types.Config) string {
+	if cfg.RootPath == "" {
+		return wd
+	}
+	root := filepath.FromSlash(cfg.RootPath)
+	if filepath.IsAbs(root) {
+		return filepath.Clean(root)
+	}
+	return filepath.Join(wd, root)
+}
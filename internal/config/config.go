@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/dakshpareek/ctx/internal/scanner"
 	"github.com/dakshpareek/ctx/internal/types"
 )
 
@@ -14,6 +15,8 @@ const (
 	DefaultRootPath = "."
 	// DefaultSkeletonPromptVersion matches the bundled prompt template version.
 	DefaultSkeletonPromptVersion = "2.1"
+	// DefaultMaxFileSize is the largest source file, in bytes, the scanner indexes.
+	DefaultMaxFileSize = 1 << 20
 )
 
 var (
@@ -49,6 +52,9 @@ func GetDefaultConfig() *types.Config {
 		ExcludedPaths:         cloneSlice(defaultExcludedPaths),
 		SkeletonPromptVersion: DefaultSkeletonPromptVersion,
 		RootPath:              DefaultRootPath,
+		RespectGitignore:      boolPtr(true),
+		MaxFileSize:           DefaultMaxFileSize,
+		FileTypes:             scanner.DefaultFileTypeRules(),
 	}
 }
 
@@ -91,6 +97,18 @@ func applyDefaults(cfg *types.Config) {
 	if cfg.RootPath == "" {
 		cfg.RootPath = DefaultRootPath
 	}
+
+	if cfg.MaxFileSize == 0 {
+		cfg.MaxFileSize = DefaultMaxFileSize
+	}
+
+	if cfg.FileTypes == nil {
+		cfg.FileTypes = scanner.DefaultFileTypeRules()
+	}
+}
+
+func boolPtr(value bool) *bool {
+	return &value
 }
 
 func cloneSlice(values []string) []string {
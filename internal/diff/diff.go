@@ -0,0 +1,214 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the number of unchanged lines shown around each change.
+const DefaultContext = 3
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type edit struct {
+	kind opKind
+	a    int // line index in old
+	b    int // line index in new
+}
+
+// Unified returns a unified diff between oldText and newText, or an empty string when they are equal.
+func Unified(oldName, newName, oldText, newText string, context int) string {
+	if oldText == newText {
+		return ""
+	}
+	if context < 0 {
+		context = DefaultContext
+	}
+
+	a := splitLines(oldText)
+	b := splitLines(newText)
+	edits := myers(a, b)
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", oldName, newName))
+
+	for start := 0; start < len(edits); {
+		// Find the next change.
+		for start < len(edits) && edits[start].kind == opEqual {
+			start++
+		}
+		if start >= len(edits) {
+			break
+		}
+
+		hunkStart := start - context
+		if hunkStart < 0 {
+			hunkStart = 0
+		}
+
+		// Extend the hunk while changes are within 2*context lines of each other.
+		end := start
+		for end < len(edits) {
+			if edits[end].kind != opEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(edits) && edits[run].kind == opEqual {
+				run++
+			}
+			if run == len(edits) || run-end > 2*context {
+				end += min(context, run-end)
+				break
+			}
+			end = run
+		}
+
+		writeHunk(&builder, edits[hunkStart:end], a, b)
+		start = end
+	}
+
+	return builder.String()
+}
+
+func writeHunk(builder *strings.Builder, edits []edit, a, b []string) {
+	var (
+		oldStart, newStart = -1, -1
+		oldCount, newCount int
+		body               strings.Builder
+	)
+
+	for _, e := range edits {
+		switch e.kind {
+		case opEqual:
+			if oldStart < 0 {
+				oldStart, newStart = e.a, e.b
+			}
+			oldCount++
+			newCount++
+			body.WriteString(" " + a[e.a])
+		case opDelete:
+			if oldStart < 0 {
+				oldStart, newStart = e.a, e.b
+			}
+			oldCount++
+			body.WriteString("-" + a[e.a])
+		case opInsert:
+			if oldStart < 0 {
+				oldStart, newStart = e.a, e.b
+			}
+			newCount++
+			body.WriteString("+" + b[e.b])
+		}
+	}
+
+	builder.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount)))
+	builder.WriteString(body.String())
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// splitLines splits text into lines that keep their newline; a missing final newline is marked like diff(1).
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	last := lines[len(lines)-1]
+	if !strings.HasSuffix(last, "\n") {
+		lines[len(lines)-1] = last + "\n\\ No newline at end of file\n"
+	}
+	return lines
+}
+
+// myers computes a shortest edit script between a and b.
+func myers(a, b []string) []edit {
+	n, m := len(a), len(b)
+	maxD := n + m
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+
+	for d := 0; d <= maxD; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, offset, d)
+			}
+		}
+	}
+	return nil
+}
+
+func backtrack(trace [][]int, a, b []string, offset, d int) []edit {
+	x, y := len(a), len(b)
+	var edits []edit
+
+	for ; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{kind: opEqual, a: x, b: y})
+		}
+		if x == prevX {
+			y--
+			edits = append(edits, edit{kind: opInsert, a: x, b: y})
+		} else {
+			x--
+			edits = append(edits, edit{kind: opDelete, a: x, b: y})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		edits = append(edits, edit{kind: opEqual, a: x, b: y})
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
@@ -0,0 +1,36 @@
+package diff
+
+import "testing"
+
+func TestUnifiedIdentical(t *testing.T) {
+	if got := Unified("a", "b", "same\n", "same\n", DefaultContext); got != "" {
+		t.Fatalf("expected empty diff, got %q", got)
+	}
+}
+
+func TestUnifiedHunks(t *testing.T) {
+	oldText := "one\ntwo\nthree\nfour\n"
+	newText := "one\ntwo\nTHREE\nfour\nfive\n"
+
+	want := "--- a/file\n+++ b/file\n@@ -1,4 +1,5 @@\n one\n two\n-three\n+THREE\n four\n+five\n"
+	if got := Unified("a/file", "b/file", oldText, newText, DefaultContext); got != want {
+		t.Fatalf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnifiedSplitsDistantChanges(t *testing.T) {
+	oldText := "a\nb\nc\nd\ne\nf\ng\nh\n"
+	newText := "A\nb\nc\nd\ne\nf\ng\nH\n"
+
+	want := "--- x\n+++ y\n@@ -1,2 +1,2 @@\n-a\n+A\n b\n@@ -7,2 +7,2 @@\n g\n-h\n+H\n"
+	if got := Unified("x", "y", oldText, newText, 1); got != want {
+		t.Fatalf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnifiedMissingTrailingNewline(t *testing.T) {
+	want := "--- x\n+++ y\n@@ -1 +1 @@\n-old\n\\ No newline at end of file\n+new\n\\ No newline at end of file\n"
+	if got := Unified("x", "y", "old", "new", DefaultContext); got != want {
+		t.Fatalf("unexpected diff:\n%q\nwant:\n%q", got, want)
+	}
+}
@@ -28,19 +28,50 @@ func EnsureDir(path string) error {
 }
 
 // WriteFile writes the provided data to the path, creating parent directories when needed.
+// The data goes to a temporary file in the same directory which is then renamed over the path,
+// so an interrupted write never leaves a truncated file behind.
 func WriteFile(path string, data []byte) error {
 	if path == "" {
 		return errors.New("file path is empty")
 	}
-	if err := EnsureDir(filepath.Dir(path)); err != nil {
+	dir := filepath.Dir(path)
+	if err := EnsureDir(dir); err != nil {
 		return err
 	}
-	if err := os.WriteFile(path, data, defaultFilePerm); err != nil {
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("write file %q: %w", path, err)
+	}
+	tmpPath := tmp.Name()
+
+	if err := writeAndSync(tmp, data); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("write file %q: %w", path, err)
+	}
+	if err := os.Chmod(tmpPath, defaultFilePerm); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("write file %q: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
 		return fmt.Errorf("write file %q: %w", path, err)
 	}
 	return nil
 }
 
+func writeAndSync(file *os.File, data []byte) error {
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
 // WriteJSON writes the provided value as pretty-formatted JSON to the given path.
 func WriteJSON(path string, value interface{}) error {
 	data, err := json.MarshalIndent(value, "", "  ")
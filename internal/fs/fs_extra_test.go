@@ -27,6 +27,30 @@ func TestEnsureDirAndWriteFile(t *testing.T) {
 	}
 }
 
+func TestWriteFileReplacesAtomically(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "index.json")
+	if err := os.WriteFile(file, []byte("old contents"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := WriteFile(file, []byte("new")); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil || string(data) != "new" {
+		t.Fatalf("unexpected file contents %q", data)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected temporary files to be cleaned up, found %d entries", len(entries))
+	}
+}
+
 func TestWriteJSONAndGitignore(t *testing.T) {
 	dir := t.TempDir()
 	target := filepath.Join(dir, "data.json")
@@ -14,6 +14,8 @@ import (
 var (
 	// ErrNotGit indicates commands that require git were executed outside a repository.
 	ErrNotGit = errors.New("not a git repository")
+	// ErrUnknownCommit indicates a recorded commit is missing or no longer an ancestor of HEAD, as after a rebase.
+	ErrUnknownCommit = errors.New("commit is not an ancestor of HEAD")
 )
 
 type commandRunner interface {
@@ -22,9 +24,17 @@ type commandRunner interface {
 
 type execCommandRunner struct{}
 
+// Run returns the command's standard output. Standard error stays out of it, since callers parse the output or
+// use it as file content; when the command fails, standard error is added to the error instead.
 func (execCommandRunner) Run(name string, args ...string) ([]byte, error) {
-	cmd := exec.Command(name, args...)
-	return cmd.CombinedOutput()
+	output, err := exec.Command(name, args...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if stderr := strings.TrimSpace(string(exitErr.Stderr)); stderr != "" {
+			return output, fmt.Errorf("%w: %s", err, stderr)
+		}
+	}
+	return output, err
 }
 
 var runner commandRunner = execCommandRunner{}
@@ -38,13 +48,14 @@ func IsGitRepo() bool {
 	return strings.TrimSpace(string(output)) == "true"
 }
 
-// GetModifiedFiles returns tracked files modified since HEAD. Requires git repository.
+// GetModifiedFiles returns tracked files modified since HEAD, relative to the current directory.
+// Requires git repository.
 func GetModifiedFiles() ([]string, error) {
 	if !IsGitRepo() {
 		return nil, ErrNotGit
 	}
 
-	output, err := runGitCommand("diff", "--name-only", "HEAD")
+	output, err := runGitCommand("diff", "--name-only", "--relative", "HEAD")
 	if err != nil {
 		return nil, fmt.Errorf("git diff --name-only: %w", err)
 	}
@@ -52,7 +63,99 @@ func GetModifiedFiles() ([]string, error) {
 	return parseGitList(output), nil
 }
 
-// GetUntrackedFiles returns files unknown to git (respecting exclude patterns). Requires git repository.
+// HeadCommit returns the SHA of HEAD. Requires a git repository with at least one commit.
+func HeadCommit() (string, error) {
+	if !IsGitRepo() {
+		return "", ErrNotGit
+	}
+
+	output, err := runGitCommand("rev-parse", "--verify", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse HEAD: %w", err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetChangedFilesSince returns tracked files that differ between commit and the working tree, relative to the
+// current directory: changes committed since commit plus staged and unstaged edits. commit need not be an ancestor
+// of HEAD, so a branch switch still diffs cleanly. It returns ErrUnknownCommit only when commit cannot be resolved,
+// such as after history was rewritten and the commit pruned. Requires git repository.
+func GetChangedFilesSince(commit string) ([]string, error) {
+	if !IsGitRepo() {
+		return nil, ErrNotGit
+	}
+
+	if _, err := runGitCommand("cat-file", "-e", commit+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCommit, commit)
+	}
+
+	output, err := runGitCommand("diff", "--name-only", "--relative", commit)
+	if err != nil {
+		return nil, fmt.Errorf("git diff --name-only %s: %w", commit, err)
+	}
+
+	return parseGitList(output), nil
+}
+
+// GetRenamesSince returns the renames git detects between commit and the working tree, mapping each new path to
+// its old path, relative to the current directory. Only renames git already knows of (staged or committed) are seen.
+// Requires git repository.
+func GetRenamesSince(commit string) (map[string]string, error) {
+	if !IsGitRepo() {
+		return nil, ErrNotGit
+	}
+
+	output, err := runGitCommand("diff", "-M", "--name-status", "--relative", "-z", commit)
+	if err != nil {
+		return nil, fmt.Errorf("git diff -M %s: %w", commit, err)
+	}
+
+	renames := make(map[string]string)
+	fields := strings.Split(string(output), "\x00")
+	for i := 0; i < len(fields); {
+		status := fields[i]
+		switch {
+		case status == "":
+			i++
+		case strings.HasPrefix(status, "R") && i+2 < len(fields):
+			renames[fields[i+2]] = fields[i+1]
+			i += 3
+		case strings.HasPrefix(status, "C"):
+			i += 3
+		default:
+			i += 2
+		}
+	}
+	return renames, nil
+}
+
+// GetStagedFiles returns files added, copied, modified or renamed in the index, relative to the current directory.
+// Staged deletions are left out. Requires git repository.
+func GetStagedFiles() ([]string, error) {
+	if !IsGitRepo() {
+		return nil, ErrNotGit
+	}
+
+	output, err := runGitCommand("diff", "--cached", "--name-only", "--relative", "--diff-filter=ACMR")
+	if err != nil {
+		return nil, fmt.Errorf("git diff --cached --name-only: %w", err)
+	}
+
+	return parseGitList(output), nil
+}
+
+// StagedContent returns the content of path as staged in the index. Path is relative to the current directory.
+func StagedContent(path string) ([]byte, error) {
+	output, err := runGitCommand("show", ":./"+filepath.ToSlash(path))
+	if err != nil {
+		return nil, fmt.Errorf("git show :%s: %w", filepath.ToSlash(path), err)
+	}
+	return output, nil
+}
+
+// GetUntrackedFiles returns files unknown to git (respecting exclude patterns), relative to the current directory.
+// Requires git repository.
 func GetUntrackedFiles() ([]string, error) {
 	if !IsGitRepo() {
 		return nil, ErrNotGit
@@ -66,6 +169,35 @@ func GetUntrackedFiles() ([]string, error) {
 	return parseGitList(output), nil
 }
 
+// TopLevel returns the absolute path of the working tree's top directory. Requires git repository.
+func TopLevel() (string, error) {
+	if !IsGitRepo() {
+		return "", ErrNotGit
+	}
+
+	output, err := runGitCommand("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse --show-toplevel: %w", err)
+	}
+
+	return filepath.FromSlash(strings.TrimSpace(string(output))), nil
+}
+
+// HooksDir returns the absolute path of the directory git runs hooks from, honoring core.hooksPath.
+// Requires git repository.
+func HooksDir() (string, error) {
+	if !IsGitRepo() {
+		return "", ErrNotGit
+	}
+
+	output, err := runGitCommand("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse --git-path hooks: %w", err)
+	}
+
+	return filepath.Abs(filepath.FromSlash(strings.TrimSpace(string(output))))
+}
+
 // GetModifiedFilesFallback walks the filesystem and returns files modified after the provided timestamp.
 func GetModifiedFilesFallback(root string, since time.Time) ([]string, error) {
 	if root == "" {
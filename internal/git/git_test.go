@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 )
@@ -104,7 +106,7 @@ func TestGetModifiedFiles(t *testing.T) {
 		},
 		{
 			name:   "git",
-			args:   []string{"diff", "--name-only", "HEAD"},
+			args:   []string{"diff", "--name-only", "--relative", "HEAD"},
 			output: []byte("file1.go\nnested/file2.ts\n"),
 		},
 	})
@@ -144,6 +146,163 @@ func TestGetModifiedFilesNotGit(t *testing.T) {
 	}
 }
 
+func TestHeadCommit(t *testing.T) {
+	fake := newFakeRunner(t, []expectedCommand{
+		{
+			name:   "git",
+			args:   []string{"rev-parse", "--is-inside-work-tree"},
+			output: []byte("true\n"),
+		},
+		{
+			name:   "git",
+			args:   []string{"rev-parse", "--verify", "HEAD"},
+			output: []byte("4b825dc642cb6eb9a060e54bf8d69288fbee4904\n"),
+		},
+	})
+	runner = fake
+	t.Cleanup(func() {
+		fake.assertAllCommandsUsed()
+		resetRunner(t)
+	})
+
+	head, err := HeadCommit()
+	if err != nil {
+		t.Fatalf("HeadCommit error: %v", err)
+	}
+	if head != "4b825dc642cb6eb9a060e54bf8d69288fbee4904" {
+		t.Fatalf("unexpected head %q", head)
+	}
+}
+
+func TestGetChangedFilesSince(t *testing.T) {
+	fake := newFakeRunner(t, []expectedCommand{
+		{
+			name:   "git",
+			args:   []string{"rev-parse", "--is-inside-work-tree"},
+			output: []byte("true\n"),
+		},
+		{
+			name: "git",
+			args: []string{"cat-file", "-e", "abc123^{commit}"},
+		},
+		{
+			name:   "git",
+			args:   []string{"diff", "--name-only", "--relative", "abc123"},
+			output: []byte("pulled.go\nedited.ts\n"),
+		},
+	})
+	runner = fake
+	t.Cleanup(func() {
+		fake.assertAllCommandsUsed()
+		resetRunner(t)
+	})
+
+	files, err := GetChangedFilesSince("abc123")
+	if err != nil {
+		t.Fatalf("GetChangedFilesSince error: %v", err)
+	}
+
+	expected := []string{"pulled.go", "edited.ts"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Fatalf("expected files %v, got %v", expected, files)
+	}
+}
+
+func TestGetChangedFilesSinceUnknownCommit(t *testing.T) {
+	fake := newFakeRunner(t, []expectedCommand{
+		{
+			name:   "git",
+			args:   []string{"rev-parse", "--is-inside-work-tree"},
+			output: []byte("true\n"),
+		},
+		{
+			name: "git",
+			args: []string{"cat-file", "-e", "abc123^{commit}"},
+			err:  errors.New("exit status 1"),
+		},
+	})
+	runner = fake
+	t.Cleanup(func() {
+		fake.assertAllCommandsUsed()
+		resetRunner(t)
+	})
+
+	if _, err := GetChangedFilesSince("abc123"); !errors.Is(err, ErrUnknownCommit) {
+		t.Fatalf("expected ErrUnknownCommit, got %v", err)
+	}
+}
+
+func TestGetRenamesSince(t *testing.T) {
+	fake := newFakeRunner(t, []expectedCommand{
+		{
+			name:   "git",
+			args:   []string{"rev-parse", "--is-inside-work-tree"},
+			output: []byte("true\n"),
+		},
+		{
+			name:   "git",
+			args:   []string{"diff", "-M", "--name-status", "--relative", "-z", "abc123"},
+			output: []byte("M\x00main.go\x00R100\x00api/user.go\x00users/user.go\x00D\x00old.go\x00R087\x00a b.ts\x00c.ts\x00"),
+		},
+	})
+	runner = fake
+	t.Cleanup(func() {
+		fake.assertAllCommandsUsed()
+		resetRunner(t)
+	})
+
+	renames, err := GetRenamesSince("abc123")
+	if err != nil {
+		t.Fatalf("GetRenamesSince error: %v", err)
+	}
+
+	expected := map[string]string{"users/user.go": "api/user.go", "c.ts": "a b.ts"}
+	if !reflect.DeepEqual(renames, expected) {
+		t.Fatalf("expected renames %v, got %v", expected, renames)
+	}
+}
+
+func TestGetStagedFilesAndContent(t *testing.T) {
+	fake := newFakeRunner(t, []expectedCommand{
+		{
+			name:   "git",
+			args:   []string{"rev-parse", "--is-inside-work-tree"},
+			output: []byte("true\n"),
+		},
+		{
+			name:   "git",
+			args:   []string{"diff", "--cached", "--name-only", "--relative", "--diff-filter=ACMR"},
+			output: []byte("api/user.go\n"),
+		},
+		{
+			name:   "git",
+			args:   []string{"show", ":./api/user.go"},
+			output: []byte("package api\n"),
+		},
+	})
+	runner = fake
+	t.Cleanup(func() {
+		fake.assertAllCommandsUsed()
+		resetRunner(t)
+	})
+
+	files, err := GetStagedFiles()
+	if err != nil {
+		t.Fatalf("GetStagedFiles error: %v", err)
+	}
+	if !reflect.DeepEqual(files, []string{"api/user.go"}) {
+		t.Fatalf("unexpected staged files %v", files)
+	}
+
+	content, err := StagedContent(files[0])
+	if err != nil {
+		t.Fatalf("StagedContent error: %v", err)
+	}
+	if string(content) != "package api\n" {
+		t.Fatalf("unexpected staged content %q", content)
+	}
+}
+
 func TestGetUntrackedFiles(t *testing.T) {
 	fake := newFakeRunner(t, []expectedCommand{
 		{
@@ -193,6 +352,35 @@ func TestGetUntrackedFilesNotGit(t *testing.T) {
 	}
 }
 
+func TestHooksDir(t *testing.T) {
+	fake := newFakeRunner(t, []expectedCommand{
+		{
+			name:   "git",
+			args:   []string{"rev-parse", "--is-inside-work-tree"},
+			output: []byte("true\n"),
+		},
+		{
+			name:   "git",
+			args:   []string{"rev-parse", "--git-path", "hooks"},
+			output: []byte(".husky\n"),
+		},
+	})
+	runner = fake
+	t.Cleanup(func() {
+		fake.assertAllCommandsUsed()
+		resetRunner(t)
+	})
+
+	dir, err := HooksDir()
+	if err != nil {
+		t.Fatalf("HooksDir error: %v", err)
+	}
+	expected, _ := filepath.Abs(".husky")
+	if dir != expected {
+		t.Fatalf("expected %s, got %s", expected, dir)
+	}
+}
+
 func TestGetModifiedFilesFallback(t *testing.T) {
 	root := t.TempDir()
 
@@ -221,3 +409,22 @@ func TestGetModifiedFilesFallback(t *testing.T) {
 		t.Fatalf("expected files %v, got %v", expected, files)
 	}
 }
+
+func TestExecCommandRunnerKeepsStderrOutOfOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	output, err := execCommandRunner{}.Run("sh", "-c", "echo content; echo warning >&2")
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if string(output) != "content\n" {
+		t.Fatalf("expected only stdout, got %q", output)
+	}
+
+	_, err = execCommandRunner{}.Run("sh", "-c", "echo fatal: bad object >&2; exit 1")
+	if err == nil || !strings.Contains(err.Error(), "fatal: bad object") {
+		t.Fatalf("expected stderr in the error, got %v", err)
+	}
+}
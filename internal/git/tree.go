@@ -0,0 +1,337 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownRevision indicates a revision that does not name a commit.
+var ErrUnknownRevision = errors.New("unknown revision")
+
+var errTreeClosed = errors.New("tree is closed")
+
+// Tree is a read-only fs.FS over the files of a commit, with paths relative to the top of the repository.
+// The listing comes from a single git ls-tree; file contents are read through one git cat-file --batch process,
+// started on the first read and stopped by Close. Symlinks and submodules are left out. Every entry reports the
+// commit time as its modification time.
+type Tree struct {
+	commit  string
+	entries map[string]*treeEntry
+
+	once     sync.Once
+	blobs    *catFile
+	blobsErr error
+}
+
+// OpenTree resolves rev to a commit and lists its files. Requires git repository.
+func OpenTree(rev string) (*Tree, error) {
+	if !IsGitRepo() {
+		return nil, ErrNotGit
+	}
+
+	output, err := runGitCommand("rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRevision, rev)
+	}
+	commit := strings.TrimSpace(string(output))
+
+	output, err = runGitCommand("show", "-s", "--format=%ct", commit)
+	if err != nil {
+		return nil, fmt.Errorf("git show %s: %w", commit, err)
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse commit time of %s: %w", commit, err)
+	}
+	modTime := time.Unix(seconds, 0).UTC()
+
+	output, err = runGitCommand("ls-tree", "-r", "-z", "--long", "--full-tree", commit)
+	if err != nil {
+		return nil, fmt.Errorf("git ls-tree %s: %w", commit, err)
+	}
+
+	tree := &Tree{
+		commit:  commit,
+		entries: map[string]*treeEntry{".": {name: ".", dir: true, modTime: modTime}},
+	}
+	for _, record := range strings.Split(string(output), "\x00") {
+		meta, name, ok := strings.Cut(record, "\t")
+		if !ok {
+			continue
+		}
+		// Each record is "<mode> <type> <object> <size>", with the size padded.
+		fields := strings.Fields(meta)
+		if len(fields) != 4 || fields[1] != "blob" || fields[0] == "120000" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse size of %s in %s: %w", name, commit, err)
+		}
+		tree.add(name, &treeEntry{
+			name:       path.Base(name),
+			object:     fields[2],
+			size:       size,
+			executable: fields[0] == "100755",
+			modTime:    modTime,
+		})
+	}
+	for _, entry := range tree.entries {
+		sort.Slice(entry.children, func(i, j int) bool {
+			return entry.children[i].Name() < entry.children[j].Name()
+		})
+	}
+	return tree, nil
+}
+
+// add records a file and creates the directories leading to it.
+func (t *Tree) add(name string, entry *treeEntry) {
+	t.entries[name] = entry
+	for {
+		parent := path.Dir(name)
+		dir, ok := t.entries[parent]
+		if !ok {
+			dir = &treeEntry{name: path.Base(parent), dir: true, modTime: entry.modTime}
+			t.entries[parent] = dir
+		}
+		dir.children = append(dir.children, t.entries[name])
+		if ok {
+			return
+		}
+		name = parent
+	}
+}
+
+// Commit returns the SHA of the commit the tree belongs to.
+func (t *Tree) Commit() string {
+	return t.commit
+}
+
+// Open implements fs.FS.
+func (t *Tree) Open(name string) (fs.File, error) {
+	entry, err := t.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return &treeFile{tree: t, path: name, entry: entry}, nil
+}
+
+// Stat implements fs.StatFS.
+func (t *Tree) Stat(name string) (fs.FileInfo, error) {
+	return t.lookup("stat", name)
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (t *Tree) ReadDir(name string) ([]fs.DirEntry, error) {
+	entry, err := t.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return append([]fs.DirEntry(nil), entry.children...), nil
+}
+
+// ReadFile implements fs.ReadFileFS.
+func (t *Tree) ReadFile(name string) ([]byte, error) {
+	entry, err := t.lookup("read", name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.dir {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
+	}
+	return t.readBlob(entry.object)
+}
+
+// Close stops the cat-file process, if one was started. Files cannot be read afterwards.
+func (t *Tree) Close() error {
+	t.once.Do(func() { t.blobsErr = errTreeClosed })
+	if t.blobs == nil {
+		return nil
+	}
+	err := t.blobs.close()
+	t.blobs, t.blobsErr = nil, errTreeClosed
+	return err
+}
+
+func (t *Tree) lookup(op, name string) (*treeEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	entry, ok := t.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return entry, nil
+}
+
+func (t *Tree) readBlob(object string) ([]byte, error) {
+	t.once.Do(func() { t.blobs, t.blobsErr = startCatFile() })
+	if t.blobsErr != nil {
+		return nil, t.blobsErr
+	}
+	return t.blobs.read(object)
+}
+
+// startCatFile starts git cat-file --batch in the current directory. Tests replace it.
+var startCatFile = func() (*catFile, error) {
+	cmd := exec.Command("git", "cat-file", "--batch")
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file --batch: %w", err)
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file --batch: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("git cat-file --batch: %w", err)
+	}
+	return &catFile{in: in, out: bufio.NewReader(out), wait: cmd.Wait}, nil
+}
+
+// catFile talks to a git cat-file --batch process: each request is an object name on a line, and each reply is a
+// "<object> <type> <size>" header followed by the content and a newline, or "<object> missing".
+// A reply it cannot parse leaves the pipe out of step with the requests, so every later read fails with that error.
+type catFile struct {
+	mu   sync.Mutex
+	in   io.WriteCloser
+	out  *bufio.Reader
+	wait func() error
+	err  error
+}
+
+func (c *catFile) read(object string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return nil, c.err
+	}
+	if _, err := io.WriteString(c.in, object+"\n"); err != nil {
+		return nil, c.fail(fmt.Errorf("git cat-file %s: %w", object, err))
+	}
+	header, err := c.out.ReadString('\n')
+	if err != nil {
+		return nil, c.fail(fmt.Errorf("git cat-file %s: %w", object, err))
+	}
+	fields := strings.Fields(header)
+	if len(fields) == 2 && fields[1] == "missing" {
+		return nil, fmt.Errorf("git cat-file %s: object missing", object)
+	}
+	if len(fields) != 3 || fields[1] != "blob" {
+		return nil, c.fail(fmt.Errorf("git cat-file %s: unexpected reply %q", object, strings.TrimSpace(header)))
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, c.fail(fmt.Errorf("git cat-file %s: unexpected reply %q", object, strings.TrimSpace(header)))
+	}
+
+	data := make([]byte, size+1)
+	if _, err := io.ReadFull(c.out, data); err != nil {
+		return nil, c.fail(fmt.Errorf("git cat-file %s: %w", object, err))
+	}
+	if data[size] != '\n' {
+		return nil, c.fail(fmt.Errorf("git cat-file %s: reply not terminated by a newline", object))
+	}
+	return data[:size], nil
+}
+
+// fail records err as the reason the process can no longer be trusted and returns it.
+func (c *catFile) fail(err error) error {
+	c.err = err
+	return err
+}
+
+func (c *catFile) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.in.Close()
+	return c.wait()
+}
+
+// treeEntry is a file or directory in a Tree. It serves as both its fs.FileInfo and fs.DirEntry.
+type treeEntry struct {
+	name       string
+	object     string
+	size       int64
+	executable bool
+	dir        bool
+	modTime    time.Time
+	children   []fs.DirEntry
+}
+
+func (e *treeEntry) Name() string       { return e.name }
+func (e *treeEntry) Size() int64        { return e.size }
+func (e *treeEntry) ModTime() time.Time { return e.modTime }
+func (e *treeEntry) IsDir() bool        { return e.dir }
+func (e *treeEntry) Sys() any           { return nil }
+func (e *treeEntry) Type() fs.FileMode  { return e.Mode().Type() }
+
+func (e *treeEntry) Info() (fs.FileInfo, error) { return e, nil }
+
+func (e *treeEntry) Mode() fs.FileMode {
+	switch {
+	case e.dir:
+		return fs.ModeDir | 0o755
+	case e.executable:
+		return 0o755
+	default:
+		return 0o644
+	}
+}
+
+// treeFile is an open Tree entry. A file's content is fetched on the first Read.
+type treeFile struct {
+	tree    *Tree
+	path    string
+	entry   *treeEntry
+	content *bytes.Reader
+	offset  int
+}
+
+func (f *treeFile) Stat() (fs.FileInfo, error) { return f.entry, nil }
+func (f *treeFile) Close() error               { return nil }
+
+func (f *treeFile) Read(p []byte) (int, error) {
+	if f.entry.dir {
+		return 0, &fs.PathError{Op: "read", Path: f.path, Err: errors.New("is a directory")}
+	}
+	if f.content == nil {
+		data, err := f.tree.readBlob(f.entry.object)
+		if err != nil {
+			return 0, &fs.PathError{Op: "read", Path: f.path, Err: err}
+		}
+		f.content = bytes.NewReader(data)
+	}
+	return f.content.Read(p)
+}
+
+// ReadDir implements fs.ReadDirFile.
+func (f *treeFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !f.entry.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: f.path, Err: errors.New("not a directory")}
+	}
+	remaining := f.entry.children[f.offset:]
+	if n > 0 && len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if n > 0 && n < len(remaining) {
+		remaining = remaining[:n]
+	}
+	f.offset += len(remaining)
+	return append([]fs.DirEntry(nil), remaining...), nil
+}
@@ -0,0 +1,178 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenTree(t *testing.T) {
+	listing := "100644 blob aaa1     12\tapi/user.go\x00" +
+		"100755 blob bbb2      3\tbin/run.sh\x00" +
+		"120000 blob ccc3      6\tlink.go\x00" +
+		"160000 commit ddd4       -\tvendor/lib\x00" +
+		"100644 blob eee5     10\tmain.go\x00"
+	fake := newFakeRunner(t, []expectedCommand{
+		{
+			name:   "git",
+			args:   []string{"rev-parse", "--is-inside-work-tree"},
+			output: []byte("true\n"),
+		},
+		{
+			name:   "git",
+			args:   []string{"rev-parse", "--verify", "--quiet", "release^{commit}"},
+			output: []byte("abc123\n"),
+		},
+		{
+			name:   "git",
+			args:   []string{"show", "-s", "--format=%ct", "abc123"},
+			output: []byte("1700000000\n"),
+		},
+		{
+			name:   "git",
+			args:   []string{"ls-tree", "-r", "-z", "--long", "--full-tree", "abc123"},
+			output: []byte(listing),
+		},
+	})
+	runner = fake
+	t.Cleanup(func() {
+		fake.assertAllCommandsUsed()
+		resetRunner(t)
+	})
+	requests := fakeCatFile(t, "aaa1 blob 12\npackage api\n\nbbb2 blob 3\nrun\n\nzzz9 missing\n")
+
+	tree, err := OpenTree("release")
+	if err != nil {
+		t.Fatalf("OpenTree error: %v", err)
+	}
+	if tree.Commit() != "abc123" {
+		t.Fatalf("unexpected commit %q", tree.Commit())
+	}
+
+	var files []string
+	err = fs.WalkDir(tree, ".", func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files = append(files, p)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WalkDir error: %v", err)
+	}
+	if expected := []string{"api/user.go", "bin/run.sh", "main.go"}; !reflect.DeepEqual(files, expected) {
+		t.Fatalf("expected files %v without symlinks and submodules, got %v", expected, files)
+	}
+
+	info, err := fs.Stat(tree, "api/user.go")
+	if err != nil {
+		t.Fatalf("Stat error: %v", err)
+	}
+	if info.Size() != 12 || !info.ModTime().Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("unexpected file info: size %d, modified %s", info.Size(), info.ModTime())
+	}
+
+	data, err := fs.ReadFile(tree, "api/user.go")
+	if err != nil || string(data) != "package api\n" {
+		t.Fatalf("ReadFile = %q, %v", data, err)
+	}
+	file, err := tree.Open("bin/run.sh")
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	if data, err := io.ReadAll(file); err != nil || string(data) != "run" {
+		t.Fatalf("Read = %q, %v", data, err)
+	}
+	if _, err := tree.readBlob("zzz9"); err == nil {
+		t.Fatalf("expected an error for a missing object")
+	}
+	if err := tree.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	if got := requests.String(); got != "aaa1\nbbb2\nzzz9\n" {
+		t.Fatalf("expected every read to share one cat-file process, got requests %q", got)
+	}
+	if _, err := tree.ReadFile("main.go"); err == nil {
+		t.Fatalf("expected reads to fail after Close")
+	}
+	if _, err := tree.Open("missing.go"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected ErrNotExist, got %v", err)
+	}
+}
+
+func TestCatFileAfterBadReplies(t *testing.T) {
+	requests := &strings.Builder{}
+	blobs := &catFile{
+		in:   nopWriteCloser{requests},
+		out:  bufio.NewReader(strings.NewReader("zzz9 missing\naaa1 blob 3\nabc\nbbb2 blob 9\nshort\nccc3 blob 2\nok\n")),
+		wait: func() error { return nil },
+	}
+
+	if _, err := blobs.read("zzz9"); err == nil {
+		t.Fatalf("expected an error for a missing object")
+	}
+	if data, err := blobs.read("aaa1"); err != nil || string(data) != "abc" {
+		t.Fatalf("expected a missing object to leave the stream in step, got %q, %v", data, err)
+	}
+
+	_, err := blobs.read("bbb2")
+	if err == nil {
+		t.Fatalf("expected an error for a short reply")
+	}
+	if _, next := blobs.read("ccc3"); next == nil || next.Error() != err.Error() {
+		t.Fatalf("expected later reads to return %v, got %v", err, next)
+	}
+	if got := requests.String(); got != "zzz9\naaa1\nbbb2\n" {
+		t.Fatalf("expected no requests after the stream desynced, got %q", got)
+	}
+}
+
+// fakeCatFile replaces the cat-file process with one that replies with replies and records the requests it gets.
+// Starting it more than once fails the test.
+func fakeCatFile(t *testing.T, replies string) *strings.Builder {
+	t.Helper()
+	requests := &strings.Builder{}
+	started := false
+	previous := startCatFile
+	startCatFile = func() (*catFile, error) {
+		if started {
+			t.Fatalf("cat-file started twice")
+		}
+		started = true
+		return &catFile{in: nopWriteCloser{requests}, out: bufio.NewReader(strings.NewReader(replies)), wait: func() error { return nil }}, nil
+	}
+	t.Cleanup(func() { startCatFile = previous })
+	return requests
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestOpenTreeUnknownRevision(t *testing.T) {
+	fake := newFakeRunner(t, []expectedCommand{
+		{
+			name:   "git",
+			args:   []string{"rev-parse", "--is-inside-work-tree"},
+			output: []byte("true\n"),
+		},
+		{
+			name: "git",
+			args: []string{"rev-parse", "--verify", "--quiet", "nope^{commit}"},
+			err:  errors.New("exit status 1"),
+		},
+	})
+	runner = fake
+	t.Cleanup(func() {
+		fake.assertAllCommandsUsed()
+		resetRunner(t)
+	})
+
+	if _, err := OpenTree("nope"); !errors.Is(err, ErrUnknownRevision) {
+		t.Fatalf("expected ErrUnknownRevision, got %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package hash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dakshpareek/ctx/internal/fs"
+)
+
+// CacheFileName is the stat cache stored next to index.json.
+const CacheFileName = "hashcache.json"
+
+// racyWindow is how recently a file may have been modified before its stat can no longer vouch for
+// its content: a write within the same timestamp tick would leave mtime and size unchanged.
+const racyWindow = 2 * time.Second
+
+// CacheEntry records the stat data a hash, and the scanner's classification of the file's content, were computed from.
+type CacheEntry struct {
+	Size       int64  `json:"size"`
+	ModTime    int64  `json:"modTime"`
+	Inode      uint64 `json:"inode,omitempty"`
+	Hash       string `json:"hash,omitempty"`
+	Classified bool   `json:"classified,omitempty"`
+	Skip       string `json:"skip,omitempty"`
+}
+
+// sameStat reports whether two entries describe the same file state.
+func (e CacheEntry) sameStat(other CacheEntry) bool {
+	return e.Size == other.Size && e.ModTime == other.ModTime && e.Inode == other.Inode
+}
+
+// Cache skips rehashing files whose size, modification time and inode are unchanged since they were last hashed,
+// and lets the scanner skip reading them to classify their content. It is safe for concurrent use.
+type Cache struct {
+	// Paranoid forces every file to be rehashed; results still refresh the cache.
+	Paranoid bool
+
+	path    string
+	mu      sync.Mutex
+	entries map[string]CacheEntry
+	dirty   bool
+}
+
+// LoadCache reads the cache at path. A missing or unreadable cache starts empty.
+func LoadCache(path string) *Cache {
+	cache := &Cache{path: path, entries: make(map[string]CacheEntry)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache.entries); err != nil || cache.entries == nil {
+		cache.entries = make(map[string]CacheEntry)
+	}
+	return cache
+}
+
+// HashFile returns the content hash for the file at fullPath, stored under key, reusing the cached
+// hash when the file's stat matches.
+func (c *Cache) HashFile(key, fullPath string, info os.FileInfo) (string, error) {
+	stat := statEntry(info)
+
+	c.mu.Lock()
+	cached, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && !c.Paranoid && cached.Hash != "" && cached.sameStat(stat) {
+		return cached.Hash, nil
+	}
+
+	value, err := HashFile(fullPath)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(info.ModTime()) < racyWindow {
+		if _, ok := c.entries[key]; ok {
+			delete(c.entries, key)
+			c.dirty = true
+		}
+		return value, nil
+	}
+
+	if cached, ok := c.entries[key]; ok && cached.sameStat(stat) {
+		stat.Classified, stat.Skip = cached.Classified, cached.Skip
+	}
+	stat.Hash = value
+	c.entries[key] = stat
+	c.dirty = true
+	return value, nil
+}
+
+// Classification returns the content classification stored for key while the file's stat matches info.
+// It implements scanner.ContentCache.
+func (c *Cache) Classification(key string, info os.FileInfo) (string, bool) {
+	if c.Paranoid {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.entries[key]
+	if !ok || !cached.Classified || !cached.sameStat(statEntry(info)) {
+		return "", false
+	}
+	return cached.Skip, true
+}
+
+// SetClassification records the content classification of the file under key, keeping its hash if the
+// stat is unchanged. Recently modified files are not recorded, as in HashFile.
+func (c *Cache) SetClassification(key string, info os.FileInfo, reason string) {
+	if time.Since(info.ModTime()) < racyWindow {
+		return
+	}
+	stat := statEntry(info)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.entries[key]; ok && cached.sameStat(stat) {
+		stat.Hash = cached.Hash
+	}
+	stat.Classified, stat.Skip = true, reason
+	c.entries[key] = stat
+	c.dirty = true
+}
+
+func statEntry(info os.FileInfo) CacheEntry {
+	return CacheEntry{Size: info.Size(), ModTime: info.ModTime().UnixNano(), Inode: inode(info)}
+}
+
+// Retain drops entries for keys not in keep.
+func (c *Cache) Retain(keep func(key string) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if !keep(key) {
+			delete(c.entries, key)
+			c.dirty = true
+		}
+	}
+}
+
+// Save writes the cache if it changed.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return fmt.Errorf("encode hash cache: %w", err)
+	}
+	if err := fs.WriteFile(c.path, data); err != nil {
+		return fmt.Errorf("write hash cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
+
+// RemoveCache deletes the cache file at path.
+func RemoveCache(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove hash cache: %w", err)
+	}
+	return nil
+}
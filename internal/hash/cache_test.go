@@ -0,0 +1,154 @@
+package hash
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeAged(t *testing.T, path, content string, modTime time.Time) os.FileInfo {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	return info
+}
+
+func TestCacheSkipsUnchangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.go")
+	cachePath := filepath.Join(dir, CacheFileName)
+	modTime := time.Now().Add(-time.Hour)
+
+	info := writeAged(t, path, "first", modTime)
+	cache := LoadCache(cachePath)
+	first, err := cache.HashFile("a.go", path, info)
+	if err != nil {
+		t.Fatalf("HashFile error: %v", err)
+	}
+	if first != HashContent([]byte("first")) {
+		t.Fatalf("unexpected hash %s", first)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	// Same size and mtime: the cached hash is trusted without reading the file.
+	info = writeAged(t, path, "other", modTime)
+	cache = LoadCache(cachePath)
+	got, err := cache.HashFile("a.go", path, info)
+	if err != nil {
+		t.Fatalf("HashFile error: %v", err)
+	}
+	if got != first {
+		t.Fatalf("expected cached hash, got %s", got)
+	}
+
+	cache.Paranoid = true
+	got, err = cache.HashFile("a.go", path, info)
+	if err != nil {
+		t.Fatalf("HashFile error: %v", err)
+	}
+	if got != HashContent([]byte("other")) {
+		t.Fatalf("expected paranoid mode to rehash, got %s", got)
+	}
+
+	info = writeAged(t, path, "changed size", modTime)
+	cache.Paranoid = false
+	got, err = cache.HashFile("a.go", path, info)
+	if err != nil {
+		t.Fatalf("HashFile error: %v", err)
+	}
+	if got != HashContent([]byte("changed size")) {
+		t.Fatalf("expected size change to force a rehash, got %s", got)
+	}
+}
+
+func TestCacheKeepsClassificationWithHash(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.js")
+	cachePath := filepath.Join(dir, CacheFileName)
+	modTime := time.Now().Add(-time.Hour)
+
+	info := writeAged(t, path, "var a=1;", modTime)
+	cache := LoadCache(cachePath)
+	if _, ok := cache.Classification("a.js", info); ok {
+		t.Fatalf("expected no classification in an empty cache")
+	}
+	cache.SetClassification("a.js", info, "minified")
+	if _, err := cache.HashFile("a.js", path, info); err != nil {
+		t.Fatalf("HashFile error: %v", err)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	cache = LoadCache(cachePath)
+	if reason, ok := cache.Classification("a.js", info); !ok || reason != "minified" {
+		t.Fatalf("expected saved classification, got %q (%v)", reason, ok)
+	}
+
+	info = writeAged(t, path, "var ab=1;", modTime)
+	if _, ok := cache.Classification("a.js", info); ok {
+		t.Fatalf("expected a size change to drop the classification")
+	}
+}
+
+func TestCacheIgnoresRecentlyModifiedFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.go")
+	modTime := time.Now()
+
+	info := writeAged(t, path, "first", modTime)
+	cache := LoadCache(filepath.Join(dir, CacheFileName))
+	if _, err := cache.HashFile("a.go", path, info); err != nil {
+		t.Fatalf("HashFile error: %v", err)
+	}
+
+	info = writeAged(t, path, "other", modTime)
+	got, err := cache.HashFile("a.go", path, info)
+	if err != nil {
+		t.Fatalf("HashFile error: %v", err)
+	}
+	if got != HashContent([]byte("other")) {
+		t.Fatalf("expected racy file to be rehashed, got %s", got)
+	}
+}
+
+func TestCacheRetainAndCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	cachePath := filepath.Join(dir, CacheFileName)
+	if err := os.WriteFile(cachePath, []byte("{not json"), 0o644); err != nil {
+		t.Fatalf("write cache: %v", err)
+	}
+
+	cache := LoadCache(cachePath)
+	path := filepath.Join(dir, "a.go")
+	info := writeAged(t, path, "content", time.Now().Add(-time.Hour))
+	if _, err := cache.HashFile("a.go", path, info); err != nil {
+		t.Fatalf("HashFile error: %v", err)
+	}
+
+	cache.Retain(func(key string) bool { return key != "a.go" })
+	if len(cache.entries) != 0 {
+		t.Fatalf("expected entry to be dropped, got %v", cache.entries)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	if err := RemoveCache(cachePath); err != nil {
+		t.Fatalf("RemoveCache error: %v", err)
+	}
+	if err := RemoveCache(cachePath); err != nil {
+		t.Fatalf("RemoveCache on missing file error: %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 )
 
@@ -16,8 +17,23 @@ func HashFile(path string) (string, error) {
 	}
 	defer file.Close()
 
+	return hashReader(file)
+}
+
+// HashFS calculates the SHA-256 hash for the file name in fsys.
+func HashFS(fsys fs.FS, name string) (string, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return "", fmt.Errorf("open file: %w", err)
+	}
+	defer file.Close()
+
+	return hashReader(file)
+}
+
+func hashReader(r io.Reader) (string, error) {
 	hasher := sha256.New()
-	if _, err := io.Copy(hasher, file); err != nil {
+	if _, err := io.Copy(hasher, r); err != nil {
 		return "", fmt.Errorf("hash file: %w", err)
 	}
 
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 )
 
 func TestHashContent(t *testing.T) {
@@ -34,6 +35,22 @@ func TestHashFile(t *testing.T) {
 	}
 }
 
+func TestHashFS(t *testing.T) {
+	fsys := fstest.MapFS{"api/user.go": {Data: []byte("hello world")}}
+
+	hash, err := HashFS(fsys, "api/user.go")
+	if err != nil {
+		t.Fatalf("HashFS error: %v", err)
+	}
+	if expected := HashContent([]byte("hello world")); hash != expected {
+		t.Fatalf("expected hash %s, got %s", expected, hash)
+	}
+
+	if _, err := HashFS(fsys, "missing.go"); err == nil {
+		t.Fatalf("expected an error for a missing file")
+	}
+}
+
 func TestHashFileLargeContent(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "large.bin")
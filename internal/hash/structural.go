@@ -0,0 +1,251 @@
+package hash
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// commentStyle describes how comments and string literals look in a family of languages.
+type commentStyle struct {
+	line       []string
+	blockStart string
+	blockEnd   string
+	quotes     string
+	// indented marks languages where line breaks and indentation carry meaning.
+	indented bool
+}
+
+var (
+	cStyle      = commentStyle{line: []string{"//"}, blockStart: "/*", blockEnd: "*/", quotes: "\"'`"}
+	rustStyle   = commentStyle{line: []string{"//"}, blockStart: "/*", blockEnd: "*/", quotes: "\""}
+	hashStyle   = commentStyle{line: []string{"#"}, quotes: "\"'"}
+	indentStyle = commentStyle{line: []string{"#"}, quotes: "\"'", indented: true}
+	plainStyle  = commentStyle{quotes: "\"'"}
+	styleForExt = map[string]commentStyle{
+		".ts": cStyle, ".tsx": cStyle, ".js": cStyle, ".jsx": cStyle, ".mjs": cStyle, ".cjs": cStyle,
+		".go": cStyle, ".java": cStyle, ".kt": cStyle, ".scala": cStyle, ".swift": cStyle, ".dart": cStyle,
+		".c": cStyle, ".h": cStyle, ".cc": cStyle, ".cpp": cStyle, ".hpp": cStyle, ".cs": cStyle, ".php": cStyle,
+		".rs": rustStyle,
+		".py": indentStyle, ".yaml": indentStyle, ".yml": indentStyle,
+		".rb": hashStyle, ".sh": hashStyle, ".bash": hashStyle, ".pl": hashStyle, ".r": hashStyle, ".toml": hashStyle,
+	}
+)
+
+// Structural returns a hash of the parts of a source file a skeleton describes, so cosmetic edits keep the same value.
+// Go files hash the package name plus exported declarations and signatures; function bodies, comments and formatting are ignored.
+// Other files, and Go files that do not parse, hash the source with comments removed and whitespace outside string
+// literals collapsed; Python and YAML keep their line breaks and indentation.
+func Structural(path string, content []byte) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".go" {
+		if normalized, ok := goDeclarations(content); ok {
+			return HashContent([]byte(normalized))
+		}
+	}
+
+	style, ok := styleForExt[ext]
+	if !ok {
+		style = plainStyle
+	}
+	return HashContent(stripSource(content, style))
+}
+
+// goDeclarations renders the exported declarations of a Go file on one line each, without comments or bodies.
+func goDeclarations(src []byte) (string, bool) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.SkipObjectResolution)
+	if err != nil {
+		return "", false
+	}
+
+	var builder strings.Builder
+	builder.WriteString("package " + file.Name.Name + "\n")
+
+	for _, decl := range file.Decls {
+		var node ast.Node
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !exportedFunc(d) {
+				continue
+			}
+			d.Doc = nil
+			d.Body = nil
+			node = d
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT {
+				continue
+			}
+			specs := exportedSpecs(d)
+			if len(specs) == 0 {
+				continue
+			}
+			d.Doc = nil
+			d.Specs = specs
+			node = d
+		default:
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, fset, node); err != nil {
+			return "", false
+		}
+		builder.WriteString(strings.Join(strings.Fields(buf.String()), " "))
+		builder.WriteByte('\n')
+	}
+
+	return builder.String(), true
+}
+
+// exportedFunc reports whether a function, or a method and its receiver type, is exported.
+func exportedFunc(d *ast.FuncDecl) bool {
+	if !ast.IsExported(d.Name.Name) {
+		return false
+	}
+	if d.Recv == nil || len(d.Recv.List) == 0 {
+		return true
+	}
+
+	expr := d.Recv.List[0].Type
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.Ident:
+			return ast.IsExported(t.Name)
+		default:
+			return true
+		}
+	}
+}
+
+// exportedSpecs keeps the specs of a declaration that declare an exported name.
+// Constant groups are kept whole when any name is exported, because iota ties their values together.
+func exportedSpecs(d *ast.GenDecl) []ast.Spec {
+	var specs []ast.Spec
+	for _, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			if ast.IsExported(s.Name.Name) {
+				specs = append(specs, s)
+			}
+		case *ast.ValueSpec:
+			for _, name := range s.Names {
+				if ast.IsExported(name.Name) {
+					specs = append(specs, s)
+					break
+				}
+			}
+		}
+	}
+
+	if d.Tok == token.CONST && len(specs) > 0 {
+		return d.Specs
+	}
+	return specs
+}
+
+// stripSource removes comments and collapses each run of whitespace outside string literals to a single space.
+// For indented styles, line breaks and leading indentation are kept instead, and blank lines are dropped.
+// Single and double quoted strings end at a newline so a stray quote cannot swallow the rest of the file.
+func stripSource(src []byte, style commentStyle) []byte {
+	out := make([]byte, 0, len(src))
+	var (
+		space       bool
+		lineStart   = style.indented
+		indentation []byte
+	)
+	// emit starts a token, writing the separator owed by the whitespace before it.
+	emit := func() {
+		switch {
+		case lineStart:
+			if len(out) > 0 {
+				out = append(out, '\n')
+			}
+			out = append(out, indentation...)
+			lineStart = false
+		case space && len(out) > 0:
+			out = append(out, ' ')
+		}
+		space = false
+	}
+
+	for i := 0; i < len(src); {
+		c := src[i]
+
+		if style.blockStart != "" && bytes.HasPrefix(src[i:], []byte(style.blockStart)) {
+			end := bytes.Index(src[i+len(style.blockStart):], []byte(style.blockEnd))
+			if end < 0 {
+				break
+			}
+			i += len(style.blockStart) + end + len(style.blockEnd)
+			continue
+		}
+
+		if lineComment(src[i:], style) {
+			end := bytes.IndexByte(src[i:], '\n')
+			if end < 0 {
+				break
+			}
+			i += end
+			continue
+		}
+
+		if strings.IndexByte(style.quotes, c) >= 0 {
+			j := i + 1
+			for j < len(src) && src[j] != c {
+				if src[j] == '\\' {
+					j++
+				} else if src[j] == '\n' && c != '`' {
+					break
+				}
+				j++
+			}
+			if j >= len(src) {
+				j = len(src) - 1
+			}
+			emit()
+			out = append(out, src[i:j+1]...)
+			i = j + 1
+			continue
+		}
+
+		if c < 0x80 && unicode.IsSpace(rune(c)) {
+			switch {
+			case style.indented && c == '\n':
+				lineStart, space, indentation = true, false, indentation[:0]
+			case lineStart:
+				indentation = append(indentation, c)
+			default:
+				space = true
+			}
+			i++
+			continue
+		}
+
+		emit()
+		out = append(out, c)
+		i++
+	}
+
+	return out
+}
+
+func lineComment(src []byte, style commentStyle) bool {
+	for _, marker := range style.line {
+		if bytes.HasPrefix(src, []byte(marker)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package hash
+
+import "testing"
+
+const goSource = `package user
+
+import "fmt"
+
+// Service manages users.
+type Service struct {
+	Name string
+	db   *DB
+}
+
+// Find looks up a user.
+func (s *Service) Find(id int) (string, error) {
+	return fmt.Sprint(id), nil
+}
+
+func helper() int { return 1 }
+`
+
+func TestStructuralGoIgnoresCosmeticEdits(t *testing.T) {
+	base := Structural("user.go", []byte(goSource))
+
+	cosmetic := []string{
+		// Comments and formatting.
+		`package user
+
+import "fmt"
+
+// Service manages users and their sessions.
+type Service struct {
+	Name string // display name
+
+	db *DB
+}
+
+// Find looks up a user by id.
+func (s *Service) Find(id int) (string, error) { return fmt.Sprint(id), nil }
+
+func helper() int { return 1 }
+`,
+		// Function bodies and unexported functions.
+		`package user
+
+import "fmt"
+
+type Service struct {
+	Name string
+	db   *DB
+}
+
+func (s *Service) Find(id int) (string, error) {
+	if id < 0 {
+		return "", fmt.Errorf("bad id")
+	}
+	return fmt.Sprint(id), nil
+}
+
+func helper() int { return 2 }
+
+func another() {}
+`,
+	}
+	for i, src := range cosmetic {
+		if got := Structural("user.go", []byte(src)); got != base {
+			t.Fatalf("case %d: expected cosmetic edit to keep the structural hash", i)
+		}
+	}
+
+	structural := []string{
+		// Signature change.
+		`package user
+
+import "fmt"
+
+type Service struct {
+	Name string
+	db   *DB
+}
+
+func (s *Service) Find(id int64) (string, error) {
+	return fmt.Sprint(id), nil
+}
+`,
+		// New exported function.
+		goSource + "\nfunc New() *Service { return nil }\n",
+		// New field.
+		`package user
+
+type Service struct {
+	Name  string
+	Email string
+	db    *DB
+}
+
+func (s *Service) Find(id int) (string, error) { return "", nil }
+`,
+	}
+	for i, src := range structural {
+		if got := Structural("user.go", []byte(src)); got == base {
+			t.Fatalf("case %d: expected structural edit to change the hash", i)
+		}
+	}
+}
+
+func TestStructuralStripsCommentsAndWhitespace(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		a, b    string
+		similar bool
+	}{
+		{
+			name:    "typescript comments and indentation",
+			path:    "app.ts",
+			a:       "export function add(a: number, b: number) {\n  return a + b;\n}\n",
+			b:       "/** Adds numbers. */\nexport function add(a: number,\n    b: number) {\n\treturn a + b; // sum\n}\n",
+			similar: true,
+		},
+		{
+			name:    "strings keep their whitespace",
+			path:    "app.ts",
+			a:       "const greeting = \"hello world\";\n",
+			b:       "const greeting = \"hello  world\";\n",
+			similar: false,
+		},
+		{
+			name:    "comment markers inside strings",
+			path:    "app.js",
+			a:       "const url = \"http://example.com\";\n",
+			b:       "const url = \"http:\";\n",
+			similar: false,
+		},
+		{
+			name:    "python hash comments",
+			path:    "tool.py",
+			a:       "def run(x):\n    return x\n",
+			b:       "# entry point\ndef run(x):  # main\n    return x\n\n",
+			similar: true,
+		},
+		{
+			name:    "code change",
+			path:    "tool.py",
+			a:       "def run(x):\n    return x\n",
+			b:       "def run(x):\n    return x + 1\n",
+			similar: false,
+		},
+		{
+			name:    "python indentation",
+			path:    "tool.py",
+			a:       "def run(x):\n    if x:\n        x += 1\n    return x\n",
+			b:       "def run(x):\n    if x:\n        x += 1\n        return x\n",
+			similar: false,
+		},
+		{
+			name:    "python line breaks",
+			path:    "tool.py",
+			a:       "x = 1\ny = 2\n",
+			b:       "x = 1 y = 2\n",
+			similar: false,
+		},
+		{
+			name:    "python spacing within a line",
+			path:    "tool.py",
+			a:       "def run(x):\n    return x + 1\n",
+			b:       "def run(x):   \n\n    return   x +\t1  \n",
+			similar: true,
+		},
+		{
+			name:    "yaml nesting",
+			path:    "config.yaml",
+			a:       "server:\n  port: 80\nhost: a\n",
+			b:       "server:\n  port: 80\n  host: a\n",
+			similar: false,
+		},
+		{
+			name:    "unparseable go falls back to stripping",
+			path:    "broken.go",
+			a:       "package broken\nfunc (\n",
+			b:       "package broken // comment\n\nfunc (\n",
+			similar: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			same := Structural(tt.path, []byte(tt.a)) == Structural(tt.path, []byte(tt.b))
+			if same != tt.similar {
+				t.Fatalf("expected similar=%v, got %v", tt.similar, same)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Package hooks installs git hook scripts that chain to the hooks already in place.
+package hooks
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// Marker identifies hook scripts written by ctx.
+	Marker = "# Installed by ctx."
+	// ChainedSuffix is appended to the name of a hook that was in place before ctx installed its own.
+	ChainedSuffix = ".pre-ctx"
+)
+
+// Install writes the hook name in dir so that it runs body. A hook ctx did not write is kept as
+// name+ChainedSuffix and runs first with the same arguments; if it fails, the hook fails as it did before.
+// Installing over an earlier ctx hook replaces it. Install reports whether an existing hook was chained.
+func Install(dir, name, body string) (bool, error) {
+	path := filepath.Join(dir, name)
+	chainedPath := path + ChainedSuffix
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return false, fmt.Errorf("create hooks directory: %w", err)
+	}
+
+	chained := false
+	owned, err := Installed(dir, name)
+	if err != nil {
+		return false, err
+	}
+	if !owned {
+		if _, err := os.Stat(path); err == nil {
+			if _, err := os.Stat(chainedPath); err == nil {
+				return false, fmt.Errorf("%s: both a hook and %s exist; merge them by hand first", path, filepath.Base(chainedPath))
+			}
+			if err := os.Rename(path, chainedPath); err != nil {
+				return false, fmt.Errorf("keep existing %s hook: %w", name, err)
+			}
+			chained = true
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return false, err
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(script(body)), 0o755); err != nil {
+		return chained, fmt.Errorf("write %s hook: %w", name, err)
+	}
+	// WriteFile keeps the mode of an existing file, so make sure a replaced ctx hook stays executable.
+	if err := os.Chmod(path, 0o755); err != nil {
+		return chained, fmt.Errorf("write %s hook: %w", name, err)
+	}
+	return chained, nil
+}
+
+// Uninstall removes the ctx hook name from dir and restores the hook it chained to.
+// Hooks ctx did not write are left alone. Uninstall reports whether a ctx hook was removed.
+func Uninstall(dir, name string) (bool, error) {
+	owned, err := Installed(dir, name)
+	if err != nil || !owned {
+		return false, err
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.Remove(path); err != nil {
+		return false, fmt.Errorf("remove %s hook: %w", name, err)
+	}
+	if _, err := os.Stat(path + ChainedSuffix); err == nil {
+		if err := os.Rename(path+ChainedSuffix, path); err != nil {
+			return true, fmt.Errorf("restore %s hook: %w", name, err)
+		}
+	}
+	return true, nil
+}
+
+// Installed reports whether the hook name in dir was written by ctx.
+func Installed(dir, name string) (bool, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return bytes.Contains(data, []byte(Marker)), nil
+}
+
+// Quote returns s as a single-quoted POSIX shell word.
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func script(body string) string {
+	var builder strings.Builder
+	builder.WriteString("#!/bin/sh\n")
+	builder.WriteString(Marker + " Remove with 'ctx hooks uninstall'.\n")
+	builder.WriteString("if [ -x \"$0" + ChainedSuffix + "\" ]; then\n")
+	builder.WriteString("\t\"$0" + ChainedSuffix + "\" \"$@\" || exit $?\n")
+	builder.WriteString("fi\n")
+	builder.WriteString(strings.TrimRight(body, "\n"))
+	builder.WriteString("\n")
+	return builder.String()
+}
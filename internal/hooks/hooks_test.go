@@ -0,0 +1,107 @@
+package hooks
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestInstallChainsExistingHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts need a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log")
+	existing := "#!/bin/sh\necho \"previous $1\" >> " + Quote(log) + "\nexit 0\n"
+	if err := os.WriteFile(filepath.Join(dir, "post-checkout"), []byte(existing), 0o755); err != nil {
+		t.Fatalf("write existing hook: %v", err)
+	}
+
+	body := "echo \"ctx $1\" >> " + Quote(log)
+	chained, err := Install(dir, "post-checkout", body)
+	if err != nil || !chained {
+		t.Fatalf("Install = %v, %v; expected existing hook chained", chained, err)
+	}
+	// Reinstalling replaces the ctx hook without chaining it to itself.
+	if chained, err := Install(dir, "post-checkout", body); err != nil || chained {
+		t.Fatalf("reinstall = %v, %v; expected no new chain", chained, err)
+	}
+
+	if out, err := exec.Command(filepath.Join(dir, "post-checkout"), "abc").CombinedOutput(); err != nil {
+		t.Fatalf("run hook: %v\n%s", err, out)
+	}
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	if string(data) != "previous abc\nctx abc\n" {
+		t.Fatalf("expected both hooks to run in order, got %q", data)
+	}
+
+	removed, err := Uninstall(dir, "post-checkout")
+	if err != nil || !removed {
+		t.Fatalf("Uninstall = %v, %v", removed, err)
+	}
+	restored, err := os.ReadFile(filepath.Join(dir, "post-checkout"))
+	if err != nil || string(restored) != existing {
+		t.Fatalf("expected original hook restored, got %q (%v)", restored, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "post-checkout"+ChainedSuffix)); !os.IsNotExist(err) {
+		t.Fatalf("expected chained copy gone, got %v", err)
+	}
+}
+
+func TestInstalledHookFailsWhenChainedHookFails(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts need a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pre-commit"), []byte("#!/bin/sh\nexit 3\n"), 0o755); err != nil {
+		t.Fatalf("write existing hook: %v", err)
+	}
+	if _, err := Install(dir, "pre-commit", "echo unreachable"); err != nil {
+		t.Fatalf("Install error: %v", err)
+	}
+
+	out, err := exec.Command(filepath.Join(dir, "pre-commit")).CombinedOutput()
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || exitErr.ExitCode() != 3 || strings.Contains(string(out), "unreachable") {
+		t.Fatalf("expected the chained hook's failure to stop the hook, got %v: %s", err, out)
+	}
+}
+
+func TestUninstallLeavesForeignHooks(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "post-merge"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("write hook: %v", err)
+	}
+
+	removed, err := Uninstall(dir, "post-merge")
+	if err != nil || removed {
+		t.Fatalf("Uninstall = %v, %v; expected foreign hook untouched", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "post-merge")); err != nil {
+		t.Fatalf("expected hook kept: %v", err)
+	}
+	if removed, err := Uninstall(dir, "post-commit"); err != nil || removed {
+		t.Fatalf("Uninstall of a missing hook = %v, %v", removed, err)
+	}
+}
+
+func TestInstallRefusesToOverwriteChainedHook(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"post-commit", "post-commit" + ChainedSuffix} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	if _, err := Install(dir, "post-commit", "true"); err == nil {
+		t.Fatalf("expected an error instead of overwriting %s", "post-commit"+ChainedSuffix)
+	}
+}
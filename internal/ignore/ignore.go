@@ -0,0 +1,184 @@
+// Package ignore implements .gitignore pattern matching without calling git.
+package ignore
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+const (
+	// GitignoreFileName is the per-directory ignore file read by the scanner.
+	GitignoreFileName = ".gitignore"
+	// CtxignoreFileName uses gitignore syntax to exclude files from ctx only. Its rules apply after .gitignore,
+	// so a negation can re-include a file git ignores.
+	CtxignoreFileName = ".ctxignore"
+)
+
+// Pattern is a single rule from an ignore file.
+type Pattern struct {
+	prefix   string
+	base     string
+	glob     string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// Matcher holds patterns in the order they apply; later patterns override earlier ones.
+// A Matcher is immutable, so one value can be shared by sibling directories scanned in parallel.
+type Matcher struct {
+	patterns []Pattern
+}
+
+// Parse reads ignore rules from data. Base is the slash-separated directory the rules are relative to,
+// empty for the project root.
+func Parse(base string, data []byte) []Pattern {
+	var patterns []Pattern
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if pattern, ok := parseLine(base, scanner.Text()); ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// ReadFile parses the ignore file name in fsys. A missing file yields no patterns.
+func ReadFile(fsys fs.FS, base, name string) ([]Pattern, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return Parse(base, data), nil
+}
+
+// ReadAncestorFile parses an ignore file from a directory above the project root. Prefix is the root's
+// slash-separated path relative to that directory, so the rules still apply to paths relative to the root.
+func ReadAncestorFile(fsys fs.FS, prefix, name string) ([]Pattern, error) {
+	patterns, err := ReadFile(fsys, "", name)
+	for i := range patterns {
+		patterns[i].prefix = prefix
+	}
+	return patterns, err
+}
+
+func parseLine(base, line string) (Pattern, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	line = trimTrailingSpaces(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return Pattern{}, false
+	}
+
+	pattern := Pattern{base: base}
+	if strings.HasPrefix(line, "!") {
+		pattern.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return Pattern{}, false
+	}
+
+	// A slash at the start or in the middle anchors the pattern to the ignore file's directory.
+	if strings.Contains(line, "/") {
+		pattern.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+
+	// gitignore has no brace expansion, so braces are literal.
+	line = strings.NewReplacer("{", `\{`, "}", `\}`).Replace(line)
+	if !doublestar.ValidatePattern(line) {
+		return Pattern{}, false
+	}
+
+	pattern.glob = line
+	return pattern, true
+}
+
+// trimTrailingSpaces drops unescaped trailing spaces, as git does.
+func trimTrailingSpaces(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+// With returns a matcher with patterns appended after the existing ones.
+func (m *Matcher) With(patterns []Pattern) *Matcher {
+	if len(patterns) == 0 {
+		return m
+	}
+
+	next := &Matcher{}
+	if m != nil {
+		next.patterns = append(next.patterns, m.patterns...)
+	}
+	next.patterns = append(next.patterns, patterns...)
+	return next
+}
+
+// Match reports whether the slash-separated path, relative to the project root, is ignored.
+// The last pattern that matches decides, so negations re-include earlier matches.
+func (m *Matcher) Match(p string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+
+	for i := len(m.patterns) - 1; i >= 0; i-- {
+		if m.patterns[i].match(p, isDir) {
+			return !m.patterns[i].negate
+		}
+	}
+	return false
+}
+
+func (p Pattern) match(target string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+
+	if p.prefix != "" {
+		target = p.prefix + "/" + target
+	}
+
+	rel := target
+	if p.base != "" {
+		if !strings.HasPrefix(target, p.base+"/") {
+			return false
+		}
+		rel = strings.TrimPrefix(target, p.base+"/")
+	}
+
+	if !p.anchored {
+		rel = path.Base(rel)
+	}
+
+	matched, err := doublestar.Match(p.glob, rel)
+	if err != nil || !matched {
+		return false
+	}
+
+	// "dir/**" matches everything inside dir but not dir itself, so negations below it still apply.
+	if prefix, ok := strings.CutSuffix(p.glob, "/**"); ok {
+		if self, _ := doublestar.Match(prefix, rel); self {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package ignore
+
+import "testing"
+
+func TestMatcherGitignoreSemantics(t *testing.T) {
+	root := Parse("", []byte(`# build output
+dist/
+*.gen.go
+/config.local.ts
+docs/**/*.md
+!docs/keep/**
+logs/**
+!logs/important.log
+\#literal
+trailing.txt   
+`))
+	nested := Parse("web", []byte(`/generated
+*.min.js
+!vendor.min.js
+`))
+	m := (*Matcher)(nil).With(root).With(nested)
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"dist", true, true},
+		{"pkg/dist", true, true},
+		{"dist", false, false},
+		{"api/types.gen.go", false, true},
+		{"api/types.go", false, false},
+		{"config.local.ts", false, true},
+		{"src/config.local.ts", false, false},
+		{"docs/guide/intro.md", false, true},
+		{"docs/intro.md", false, true},
+		{"docs/keep/intro.md", false, false},
+		{"logs", true, false},
+		{"logs/debug.log", false, true},
+		{"logs/important.log", false, false},
+		{"#literal", false, true},
+		{"trailing.txt", false, true},
+		{"web/generated", true, true},
+		{"web/src/generated", true, false},
+		{"generated", true, false},
+		{"web/app.min.js", false, true},
+		{"web/vendor.min.js", false, false},
+		{"app.min.js", false, false},
+	}
+
+	for _, tt := range tests {
+		if got := m.Match(tt.path, tt.isDir); got != tt.ignored {
+			t.Errorf("Match(%q, dir=%v) = %v, want %v", tt.path, tt.isDir, got, tt.ignored)
+		}
+	}
+}
+
+func TestNilMatcherIgnoresNothing(t *testing.T) {
+	var m *Matcher
+	if m.Match("anything.go", false) {
+		t.Fatalf("expected nil matcher to match nothing")
+	}
+	if m.With(nil) != nil {
+		t.Fatalf("expected adding no patterns to keep the nil matcher")
+	}
+}
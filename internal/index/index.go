@@ -5,15 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"time"
 
+	"github.com/dakshpareek/ctx/internal/fs"
 	"github.com/dakshpareek/ctx/internal/types"
 )
 
 const (
-	// DefaultIndexVersion represents the initial version written to a new index.
-	DefaultIndexVersion = "1.0.0"
+	// DefaultIndexVersion is the index schema version written by this build. Older indexes are
+	// migrated on load; newer ones are refused.
+	DefaultIndexVersion = "1.1.0"
 	// DefaultPromptVersion represents the default prompt template version.
 	DefaultPromptVersion = "2.1"
 )
@@ -40,22 +41,23 @@ func CreateEmptyIndex() *types.Index {
 }
 
 // LoadIndex reads an index file from disk and unmarshals it into memory.
+// Indexes from an older schema are migrated in memory; the file is upgraded, after a backup, by the next
+// SaveIndex. Indexes from a newer ctx return ErrNewerIndex.
 func LoadIndex(path string) (*types.Index, error) {
-	file, err := os.Open(path)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("open index: %w", err)
 	}
-	defer file.Close()
 
-	var idx types.Index
-	if err := json.NewDecoder(file).Decode(&idx); err != nil {
-		return nil, fmt.Errorf("decode index: %w", err)
+	idx, err := decodeIndex(data)
+	if err != nil {
+		return nil, err
 	}
 
-	ensureIndexInitialized(&idx)
-	idx.Stats = CalculateStats(&idx)
+	ensureIndexInitialized(idx)
+	idx.Stats = CalculateStats(idx)
 
-	return &idx, nil
+	return idx, nil
 }
 
 // SaveIndex writes the provided index structure to disk with stable formatting.
@@ -65,6 +67,7 @@ func SaveIndex(idx *types.Index, path string) error {
 	}
 
 	ensureIndexInitialized(idx)
+	idx.Version = DefaultIndexVersion
 	idx.Stats = CalculateStats(idx)
 
 	data, err := json.MarshalIndent(idx, "", "  ")
@@ -73,12 +76,10 @@ func SaveIndex(idx *types.Index, path string) error {
 	}
 	data = append(data, '\n')
 
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return fmt.Errorf("create index directory: %w", err)
+	if err := backupOlderIndex(path); err != nil {
+		return err
 	}
-
-	if err := os.WriteFile(path, data, 0o644); err != nil {
+	if err := fs.WriteFile(path, data); err != nil {
 		return fmt.Errorf("write index: %w", err)
 	}
 
@@ -115,31 +116,48 @@ func RemoveFileEntry(idx *types.Index, path string) error {
 	return nil
 }
 
-// CalculateStats recomputes index statistics based on the current file set.
+// CalculateStats recomputes index statistics, overall and per package, based on the current file set.
 func CalculateStats(idx *types.Index) types.IndexStats {
 	if idx == nil {
 		return types.IndexStats{}
 	}
 
-	var stats types.IndexStats
+	var (
+		stats    types.IndexStats
+		packages map[string]types.IndexStats
+	)
 	for _, entry := range idx.Files {
-		stats.TotalFiles++
-		switch entry.Status {
-		case types.StatusCurrent:
-			stats.Current++
-		case types.StatusStale:
-			stats.Stale++
-		case types.StatusMissing:
-			stats.Missing++
-		case types.StatusPendingGeneration:
-			stats.PendingGeneration++
+		countStatus(&stats, entry.Status)
+		if entry.Package == "" {
+			continue
 		}
+		if packages == nil {
+			packages = make(map[string]types.IndexStats)
+		}
+		pkg := packages[entry.Package]
+		countStatus(&pkg, entry.Status)
+		packages[entry.Package] = pkg
 	}
 
 	idx.Stats = stats
+	idx.PackageStats = packages
 	return stats
 }
 
+func countStatus(stats *types.IndexStats, status types.Status) {
+	stats.TotalFiles++
+	switch status {
+	case types.StatusCurrent:
+		stats.Current++
+	case types.StatusStale:
+		stats.Stale++
+	case types.StatusMissing:
+		stats.Missing++
+	case types.StatusPendingGeneration:
+		stats.PendingGeneration++
+	}
+}
+
 func ensureIndexInitialized(idx *types.Index) {
 	if idx.Files == nil {
 		idx.Files = make(map[string]types.FileEntry)
@@ -81,7 +81,6 @@ func TestSaveAndLoadIndex(t *testing.T) {
 	indexPath := filepath.Join(tmpDir, "index.json")
 
 	idx := CreateEmptyIndex()
-	idx.Version = "1.0.1"
 	idx.PromptVersion = "3.0"
 	idx.LastSync = time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
 
@@ -120,8 +119,8 @@ func TestSaveAndLoadIndex(t *testing.T) {
 		t.Fatalf("LoadIndex error: %v", err)
 	}
 
-	if loaded.Version != idx.Version {
-		t.Fatalf("expected version %q, got %q", idx.Version, loaded.Version)
+	if loaded.Version != DefaultIndexVersion {
+		t.Fatalf("expected version %q, got %q", DefaultIndexVersion, loaded.Version)
 	}
 	if loaded.PromptVersion != idx.PromptVersion {
 		t.Fatalf("expected prompt version %q, got %q", idx.PromptVersion, loaded.PromptVersion)
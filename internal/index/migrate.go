@@ -0,0 +1,211 @@
+package index
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dakshpareek/ctx/internal/fs"
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+// legacyIndexVersion is assumed for indexes written before the version field was populated.
+const legacyIndexVersion = "1.0.0"
+
+// ErrNewerIndex is returned when an index was written by a newer ctx than this build understands.
+var ErrNewerIndex = errors.New("index was written by a newer version of ctx")
+
+// migration upgrades a decoded index document to the schema version in to.
+type migration struct {
+	to          string
+	description string
+	apply       func(doc map[string]any) error
+}
+
+// migrations lists every schema upgrade in order. Append new entries and bump DefaultIndexVersion
+// whenever the on-disk format changes.
+var migrations = []migration{
+	{
+		to:          "1.1.0",
+		description: "record the prompt version on each generated file entry",
+		apply:       migrateEntryPromptVersions,
+	},
+}
+
+// BackupPath returns where SaveIndex keeps the original index before overwriting a version older than this build.
+func BackupPath(path, version string) string {
+	return path + ".bak-" + version
+}
+
+// decodeIndex parses index data, migrating older schemas in memory.
+func decodeIndex(data []byte) (*types.Index, error) {
+	version, err := schemaVersion(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode index: %w", err)
+	}
+
+	cmp, err := compareVersions(version, DefaultIndexVersion)
+	if err != nil {
+		return nil, fmt.Errorf("decode index: %w", err)
+	}
+	if cmp > 0 {
+		return nil, fmt.Errorf("%w: schema %s is newer than the supported %s; upgrade ctx", ErrNewerIndex, version, DefaultIndexVersion)
+	}
+	if cmp < 0 {
+		if data, err = migrate(data, version); err != nil {
+			return nil, err
+		}
+	}
+
+	var idx types.Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("decode index: %w", err)
+	}
+	return &idx, nil
+}
+
+func migrate(data []byte, version string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc map[string]any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode index: %w", err)
+	}
+
+	for _, m := range migrations {
+		if cmp, _ := compareVersions(version, m.to); cmp >= 0 {
+			continue
+		}
+		if err := m.apply(doc); err != nil {
+			return nil, fmt.Errorf("migrate index to %s (%s): %w", m.to, m.description, err)
+		}
+		version = m.to
+		doc["version"] = version
+	}
+	doc["version"] = DefaultIndexVersion
+
+	return json.Marshal(doc)
+}
+
+// schemaVersion returns the version recorded in index data, treating a missing field as the legacy schema.
+func schemaVersion(data []byte) (string, error) {
+	var header struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return "", err
+	}
+	if header.Version == "" {
+		return legacyIndexVersion, nil
+	}
+	return header.Version, nil
+}
+
+// backupOlderIndex copies the index at path aside when it was written with an older schema, so the first
+// save that upgrades it keeps the original. Loading only migrates in memory; the backup is written here
+// because saves happen in commands that hold the workspace lock.
+func backupOlderIndex(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("back up index: %w", err)
+	}
+	version, err := schemaVersion(data)
+	if err != nil {
+		// An unreadable index is replaced, not migrated; there is no schema to record.
+		return nil
+	}
+	if cmp, err := compareVersions(version, DefaultIndexVersion); err != nil || cmp >= 0 {
+		return nil
+	}
+
+	backup := BackupPath(path, version)
+	if fs.Exists(backup) {
+		return nil
+	}
+	if err := fs.WriteFile(backup, data); err != nil {
+		return fmt.Errorf("back up index: %w", err)
+	}
+	return nil
+}
+
+// migrateEntryPromptVersions copies the index-wide prompt version onto generated entries, which
+// previously had no per-entry record of the template they came from.
+func migrateEntryPromptVersions(doc map[string]any) error {
+	promptVersion, _ := doc["promptVersion"].(string)
+	if promptVersion == "" {
+		promptVersion = DefaultPromptVersion
+		doc["promptVersion"] = promptVersion
+	}
+
+	if cfg, ok := doc["config"].(map[string]any); ok {
+		if v, _ := cfg["skeletonPromptVersion"].(string); v == "" {
+			cfg["skeletonPromptVersion"] = promptVersion
+		}
+	}
+
+	files, _ := doc["files"].(map[string]any)
+	for path, raw := range files {
+		entry, ok := raw.(map[string]any)
+		if !ok {
+			return fmt.Errorf("entry %s is not an object", path)
+		}
+		status, _ := entry["status"].(string)
+		if status != string(types.StatusCurrent) && status != string(types.StatusPendingGeneration) {
+			continue
+		}
+		if v, _ := entry["promptVersion"].(string); v == "" {
+			entry["promptVersion"] = promptVersion
+		}
+	}
+	return nil
+}
+
+// compareVersions compares dotted numeric versions, returning -1, 0 or 1.
+func compareVersions(a, b string) (int, error) {
+	pa, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	pb, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		switch {
+		case x < y:
+			return -1, nil
+		case x > y:
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func parseVersion(version string) ([]int, error) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid index version %q", version)
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
@@ -0,0 +1,130 @@
+package index
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+const legacyIndex = `{
+  "version": "1.0.0",
+  "promptVersion": "2.0",
+  "lastSync": "2024-05-01T08:30:00Z",
+  "config": {"includedExtensions": [".go"], "excludedPaths": null, "skeletonPromptVersion": "", "rootPath": "."},
+  "files": {
+    "main.go": {"path": "main.go", "hash": "h1", "skeletonHash": "s1", "skeletonPath": ".ctx/skeletons/main.skeleton.go", "lastModified": "2024-05-01T08:00:00Z", "status": "current", "type": "", "size": 12},
+    "util.go": {"path": "util.go", "hash": "h2", "skeletonHash": "", "skeletonPath": ".ctx/skeletons/util.skeleton.go", "lastModified": "2024-05-01T08:00:00Z", "status": "missing", "type": "", "size": 34}
+  },
+  "stats": {"totalFiles": 2, "current": 1, "stale": 0, "missing": 1, "pendingGeneration": 0}
+}
+`
+
+func TestLoadIndexMigratesLegacySchema(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "index.json")
+	if err := os.WriteFile(indexPath, []byte(legacyIndex), 0o644); err != nil {
+		t.Fatalf("write index: %v", err)
+	}
+
+	idx, err := LoadIndex(indexPath)
+	if err != nil {
+		t.Fatalf("LoadIndex error: %v", err)
+	}
+
+	if idx.Version != DefaultIndexVersion {
+		t.Fatalf("expected migrated version %q, got %q", DefaultIndexVersion, idx.Version)
+	}
+	if got := idx.Files["main.go"].PromptVersion; got != "2.0" {
+		t.Fatalf("expected current entry to record prompt version 2.0, got %q", got)
+	}
+	if got := idx.Files["util.go"].PromptVersion; got != "" {
+		t.Fatalf("expected missing entry to stay unversioned, got %q", got)
+	}
+	if got := idx.Config.SkeletonPromptVersion; got != "2.0" {
+		t.Fatalf("expected config prompt version 2.0, got %q", got)
+	}
+	if got := idx.Files["util.go"].Size; got != 34 {
+		t.Fatalf("expected size to survive migration, got %d", got)
+	}
+
+	if _, err := os.Stat(BackupPath(indexPath, "1.0.0")); !os.IsNotExist(err) {
+		t.Fatalf("expected loading alone not to write a backup")
+	}
+	if data, _ := os.ReadFile(indexPath); string(data) != legacyIndex {
+		t.Fatalf("expected loading alone to leave the index file untouched")
+	}
+
+	if err := SaveIndex(idx, indexPath); err != nil {
+		t.Fatalf("SaveIndex error: %v", err)
+	}
+	backup, err := os.ReadFile(BackupPath(indexPath, "1.0.0"))
+	if err != nil {
+		t.Fatalf("expected backup before the upgrading save: %v", err)
+	}
+	if string(backup) != legacyIndex {
+		t.Fatalf("expected backup to hold the original index")
+	}
+	if err := SaveIndex(idx, indexPath); err != nil {
+		t.Fatalf("SaveIndex error: %v", err)
+	}
+	if _, err := LoadIndex(indexPath); err != nil {
+		t.Fatalf("LoadIndex after save error: %v", err)
+	}
+	if backup, _ := os.ReadFile(BackupPath(indexPath, "1.0.0")); string(backup) != legacyIndex {
+		t.Fatalf("expected existing backup to be kept")
+	}
+}
+
+func TestLoadIndexWithoutVersionIsMigrated(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "index.json")
+	data := `{"files": {"a.go": {"path": "a.go", "status": "pendingGeneration"}}}`
+	if err := os.WriteFile(indexPath, []byte(data), 0o644); err != nil {
+		t.Fatalf("write index: %v", err)
+	}
+
+	idx, err := LoadIndex(indexPath)
+	if err != nil {
+		t.Fatalf("LoadIndex error: %v", err)
+	}
+	if got := idx.Files["a.go"].PromptVersion; got != DefaultPromptVersion {
+		t.Fatalf("expected default prompt version, got %q", got)
+	}
+	if idx.Files["a.go"].Status != types.StatusPendingGeneration {
+		t.Fatalf("expected status to survive migration")
+	}
+}
+
+func TestLoadIndexRefusesNewerSchema(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "index.json")
+	if err := os.WriteFile(indexPath, []byte(`{"version": "99.0.0", "files": {}}`), 0o644); err != nil {
+		t.Fatalf("write index: %v", err)
+	}
+
+	if _, err := LoadIndex(indexPath); !errors.Is(err, ErrNewerIndex) {
+		t.Fatalf("expected ErrNewerIndex, got %v", err)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.1.0", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"1.1", "1.1.0", 0},
+		{"v2.0.0", "1.1.0", 1},
+	}
+	for _, tt := range tests {
+		got, err := compareVersions(tt.a, tt.b)
+		if err != nil || got != tt.want {
+			t.Fatalf("compareVersions(%q, %q) = %d, %v; expected %d", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+
+	if _, err := compareVersions("1.x", "1.0"); err == nil {
+		t.Fatalf("expected error for invalid version")
+	}
+}
@@ -0,0 +1,164 @@
+// Package lock implements the advisory lock file that serializes mutating ctx commands.
+package lock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// FileName is the lock file created inside .ctx while a mutating command runs.
+	FileName = "lock"
+	// DefaultTimeout is how long Acquire waits for another process to release the lock.
+	DefaultTimeout = 10 * time.Second
+	// StaleAfter is the age after which a lock held from another host is considered abandoned, since its holder cannot be checked.
+	StaleAfter = time.Hour
+
+	pollInterval = 100 * time.Millisecond
+)
+
+// ErrLocked is returned when another live process holds the lock.
+var ErrLocked = errors.New("workspace is locked")
+
+// Owner describes the process holding a lock.
+type Owner struct {
+	PID      int       `json:"pid"`
+	Hostname string    `json:"hostname"`
+	Command  string    `json:"command,omitempty"`
+	Acquired time.Time `json:"acquired"`
+}
+
+// Lock is a held lock file.
+type Lock struct {
+	path string
+}
+
+// Acquire creates the lock file at path, waiting up to timeout for a live holder to release it.
+// Locks left behind by dead processes on this host, or held from another host for longer than StaleAfter, are removed.
+func Acquire(path, command string, timeout time.Duration) (*Lock, error) {
+	hostname, _ := os.Hostname()
+	owner := Owner{PID: os.Getpid(), Hostname: hostname, Command: command}
+	deadline := time.Now().Add(timeout)
+
+	for {
+		owner.Acquired = time.Now().UTC()
+		err := create(path, owner)
+		if err == nil {
+			return &Lock{path: path}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("create lock %s: %w", path, err)
+		}
+
+		data, holder, readErr := readOwner(path)
+		if readErr != nil && errors.Is(readErr, os.ErrNotExist) {
+			continue
+		}
+		if isStale(path, holder, readErr, hostname) {
+			if err := breakStale(path, data); err != nil {
+				return nil, fmt.Errorf("remove stale lock %s: %w", path, err)
+			}
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, lockedError(path, holder)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// Release removes the lock file.
+func (l *Lock) Release() error {
+	if l == nil {
+		return nil
+	}
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("release lock %s: %w", l.path, err)
+	}
+	return nil
+}
+
+func create(path string, owner Owner) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(owner)
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+	return file.Close()
+}
+
+// readOwner returns the lock file's content and the holder it describes.
+func readOwner(path string) ([]byte, Owner, error) {
+	var owner Owner
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, owner, err
+	}
+	if err := json.Unmarshal(data, &owner); err != nil {
+		return data, owner, err
+	}
+	return data, owner, nil
+}
+
+// breakStale removes the lock at path if it still holds stale, the content judged stale. Removing by path alone
+// would race: a waiter that saw the same stale lock could delete the lock another waiter has just created in its
+// place. Instead the file is renamed aside, which only one waiter can do, and its content is checked; a newer
+// lock moved by mistake is linked back, which fails rather than overwrite a lock created in the meantime.
+func breakStale(path string, stale []byte) error {
+	aside := fmt.Sprintf("%s.stale-%d-%d", path, os.Getpid(), time.Now().UnixNano())
+	if err := os.Rename(path, aside); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer os.Remove(aside)
+
+	moved, err := os.ReadFile(aside)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(moved, stale) {
+		return nil
+	}
+	if err := os.Link(aside, path); err != nil && !errors.Is(err, os.ErrExist) {
+		return err
+	}
+	return nil
+}
+
+func isStale(path string, holder Owner, readErr error, hostname string) bool {
+	if readErr != nil {
+		// A holder writes its details right after creating the file; give it a moment before giving up on it.
+		info, err := os.Stat(path)
+		return err == nil && time.Since(info.ModTime()) > time.Second
+	}
+	// A holder on this host can be checked directly, however long it has been running.
+	if holder.Hostname == hostname {
+		return !processAlive(holder.PID)
+	}
+	return time.Since(holder.Acquired) > StaleAfter
+}
+
+func lockedError(path string, holder Owner) error {
+	who := "another ctx process"
+	if holder.PID > 0 {
+		who = fmt.Sprintf("ctx (pid %d", holder.PID)
+		if holder.Command != "" {
+			who += ", " + holder.Command
+		}
+		who += ")"
+	}
+	return fmt.Errorf("%w: %s has held %s since %s; remove the file if that process is gone", ErrLocked, who, path, holder.Acquired.Local().Format(time.RFC3339))
+}
@@ -0,0 +1,140 @@
+package lock
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquireAndRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	held, err := Acquire(path, "ctx sync", time.Second)
+	if err != nil {
+		t.Fatalf("Acquire error: %v", err)
+	}
+
+	_, owner, err := readOwner(path)
+	if err != nil {
+		t.Fatalf("read owner: %v", err)
+	}
+	if owner.PID != os.Getpid() || owner.Command != "ctx sync" {
+		t.Fatalf("unexpected owner %+v", owner)
+	}
+
+	if _, err := Acquire(path, "ctx update", 0); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked while held, got %v", err)
+	}
+
+	if err := held.Release(); err != nil {
+		t.Fatalf("Release error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected lock file removed, got %v", err)
+	}
+
+	again, err := Acquire(path, "ctx update", 0)
+	if err != nil {
+		t.Fatalf("expected lock to be free after release: %v", err)
+	}
+	again.Release()
+}
+
+func TestAcquireWaitsForRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	held, err := Acquire(path, "ctx sync", 0)
+	if err != nil {
+		t.Fatalf("Acquire error: %v", err)
+	}
+
+	go func() {
+		time.Sleep(2 * pollInterval)
+		held.Release()
+	}()
+
+	next, err := Acquire(path, "ctx update", 5*time.Second)
+	if err != nil {
+		t.Fatalf("expected to acquire after release: %v", err)
+	}
+	next.Release()
+}
+
+func TestAcquireRemovesStaleLocks(t *testing.T) {
+	hostname, _ := os.Hostname()
+	tests := map[string]Owner{
+		"dead process": {PID: 1 << 30, Hostname: hostname, Acquired: time.Now().UTC()},
+		"expired":      {PID: os.Getpid(), Hostname: "elsewhere", Acquired: time.Now().Add(-2 * StaleAfter)},
+	}
+
+	for name, owner := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), FileName)
+			data, _ := json.Marshal(owner)
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				t.Fatalf("write lock: %v", err)
+			}
+
+			held, err := Acquire(path, "ctx sync", 0)
+			if err != nil {
+				t.Fatalf("expected stale lock to be replaced: %v", err)
+			}
+			held.Release()
+		})
+	}
+}
+
+func TestAcquireKeepsLiveForeignLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	owner := Owner{PID: 1 << 30, Hostname: "another-host", Acquired: time.Now().UTC()}
+	data, _ := json.Marshal(owner)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+
+	if _, err := Acquire(path, "ctx sync", 0); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected lock from another host to be respected, got %v", err)
+	}
+}
+
+func TestAcquireKeepsLongRunningLocalLock(t *testing.T) {
+	hostname, _ := os.Hostname()
+	path := filepath.Join(t.TempDir(), FileName)
+	owner := Owner{PID: os.Getpid(), Hostname: hostname, Acquired: time.Now().Add(-2 * StaleAfter)}
+	data, _ := json.Marshal(owner)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+
+	if _, err := Acquire(path, "ctx sync", 0); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected a live local holder to keep its lock, got %v", err)
+	}
+}
+
+func TestBreakStaleKeepsReplacedLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte("fresh\n"), 0o644); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+
+	if err := breakStale(path, []byte("stale\n")); err != nil {
+		t.Fatalf("breakStale error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "fresh\n" {
+		t.Fatalf("expected the newer lock to be restored, got %q (%v)", data, err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Fatalf("expected only the lock file to remain, got %d entries", len(entries))
+	}
+
+	if err := breakStale(path, []byte("fresh\n")); err != nil {
+		t.Fatalf("breakStale error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected the stale lock to be removed")
+	}
+}
@@ -0,0 +1,245 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+const (
+	// DefaultConcurrency is the number of prompts sent in parallel when not configured.
+	DefaultConcurrency = 4
+	// DefaultMaxRetries is the number of retries for retryable failures when not configured.
+	DefaultMaxRetries = 3
+	// DefaultTimeout bounds a single HTTP request when not configured.
+	DefaultTimeout = 120 * time.Second
+
+	defaultBackoff = 500 * time.Millisecond
+)
+
+var (
+	// ErrNotConfigured is returned when config.json has no provider section.
+	ErrNotConfigured = errors.New("provider not configured: add a \"provider\" section to .ctx/config.json")
+)
+
+// Client sends prompts to an OpenAI-compatible chat-completions endpoint.
+type Client struct {
+	endpoint   string
+	model      string
+	apiKey     string
+	maxRetries int
+	httpClient *http.Client
+	backoff    time.Duration
+	sleep      func(context.Context, time.Duration) error
+}
+
+// NewClient validates the provider configuration and resolves the API key from the environment.
+func NewClient(cfg *types.ProviderConfig) (*Client, error) {
+	if cfg == nil {
+		return nil, ErrNotConfigured
+	}
+	if strings.TrimSpace(cfg.BaseURL) == "" {
+		return nil, errors.New("provider baseUrl is required")
+	}
+	if strings.TrimSpace(cfg.Model) == "" {
+		return nil, errors.New("provider model is required")
+	}
+
+	var apiKey string
+	if cfg.APIKeyEnv != "" {
+		apiKey = os.Getenv(cfg.APIKeyEnv)
+		if apiKey == "" {
+			return nil, fmt.Errorf("environment variable %s is not set", cfg.APIKeyEnv)
+		}
+	}
+
+	timeout := DefaultTimeout
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+
+	return &Client{
+		endpoint:   strings.TrimRight(cfg.BaseURL, "/") + "/chat/completions",
+		model:      cfg.Model,
+		apiKey:     apiKey,
+		maxRetries: MaxRetries(cfg),
+		httpClient: &http.Client{Timeout: timeout},
+		backoff:    defaultBackoff,
+		sleep:      sleepContext,
+	}, nil
+}
+
+// Concurrency returns the configured parallelism, falling back to DefaultConcurrency.
+func Concurrency(cfg *types.ProviderConfig) int {
+	if cfg == nil || cfg.Concurrency <= 0 {
+		return DefaultConcurrency
+	}
+	return cfg.Concurrency
+}
+
+// MaxRetries returns the configured retry budget, falling back to DefaultMaxRetries when it is unset.
+// Zero turns retries off.
+func MaxRetries(cfg *types.ProviderConfig) int {
+	if cfg == nil || cfg.MaxRetries == nil {
+		return DefaultMaxRetries
+	}
+	return max(*cfg.MaxRetries, 0)
+}
+
+// Endpoint returns the chat-completions URL requests are sent to.
+func (c *Client) Endpoint() string {
+	return c.endpoint
+}
+
+// Model returns the model name sent with each request.
+func (c *Client) Model() string {
+	return c.model
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+type statusError struct {
+	code       int
+	body       string
+	retryAfter time.Duration
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("provider returned %d: %s", e.code, e.body)
+}
+
+// Complete sends a single-message chat completion and returns the assistant reply.
+// Network errors, 429 and 5xx responses are retried with exponential backoff.
+func (c *Client) Complete(ctx context.Context, prompt string) (string, error) {
+	payload, err := json.Marshal(chatRequest{
+		Model:    c.model,
+		Messages: []chatMessage{{Role: "user", Content: prompt}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("encode request: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			delay := c.backoff << (attempt - 1)
+			var statusErr *statusError
+			if errors.As(lastErr, &statusErr) && statusErr.retryAfter > delay {
+				delay = statusErr.retryAfter
+			}
+			if err := c.sleep(ctx, delay); err != nil {
+				break
+			}
+		}
+
+		reply, err := c.send(ctx, payload)
+		if err == nil {
+			return reply, nil
+		}
+		lastErr = err
+
+		if ctx.Err() != nil || !retryable(err) {
+			break
+		}
+	}
+
+	return "", lastErr
+}
+
+// sleepContext waits for d, returning early with the context's error when it is cancelled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (c *Client) send(ctx context.Context, payload []byte) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		statusErr := &statusError{code: resp.StatusCode, body: strings.TrimSpace(string(body))}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			statusErr.retryAfter = time.Duration(seconds) * time.Second
+		}
+		return "", statusErr
+	}
+
+	var decoded chatResponse
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return "", &decodeError{err: err}
+	}
+	if len(decoded.Choices) == 0 || strings.TrimSpace(decoded.Choices[0].Message.Content) == "" {
+		return "", &decodeError{err: errors.New("response contained no choices")}
+	}
+
+	return decoded.Choices[0].Message.Content, nil
+}
+
+type decodeError struct {
+	err error
+}
+
+func (e *decodeError) Error() string {
+	return fmt.Sprintf("decode response: %v", e.err)
+}
+
+func (e *decodeError) Unwrap() error {
+	return e.err
+}
+
+func retryable(err error) bool {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code == http.StatusTooManyRequests || statusErr.code >= http.StatusInternalServerError
+	}
+	var decodeErr *decodeError
+	return !errors.As(err, &decodeErr)
+}
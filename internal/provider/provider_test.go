@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, cfg types.ProviderConfig) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg.BaseURL = server.URL + "/v1/"
+	if cfg.Model == "" {
+		cfg.Model = "test-model"
+	}
+	client, err := NewClient(&cfg)
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	client.sleep = func(context.Context, time.Duration) error { return nil }
+	return client
+}
+
+func retries(n int) *int {
+	return &n
+}
+
+func writeReply(w http.ResponseWriter, content string) {
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"choices": []map[string]interface{}{
+			{"message": map[string]string{"role": "assistant", "content": content}},
+		},
+	})
+}
+
+func TestCompleteSendsChatRequest(t *testing.T) {
+	t.Setenv("CTX_TEST_KEY", "secret")
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("unexpected authorization header %q", got)
+		}
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if req.Model != "test-model" || len(req.Messages) != 1 || req.Messages[0].Content != "prompt" {
+			t.Errorf("unexpected request %#v", req)
+		}
+		writeReply(w, "skeleton")
+	}, types.ProviderConfig{APIKeyEnv: "CTX_TEST_KEY"})
+
+	reply, err := client.Complete(context.Background(), "prompt")
+	if err != nil {
+		t.Fatalf("Complete error: %v", err)
+	}
+	if reply != "skeleton" {
+		t.Fatalf("expected reply %q, got %q", "skeleton", reply)
+	}
+}
+
+func TestCompleteRetriesServerErrors(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "busy", http.StatusTooManyRequests)
+			return
+		}
+		writeReply(w, "ok")
+	}, types.ProviderConfig{})
+
+	var delays []time.Duration
+	client.sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	reply, err := client.Complete(context.Background(), "prompt")
+	if err != nil {
+		t.Fatalf("Complete error: %v", err)
+	}
+	if reply != "ok" || calls != 3 {
+		t.Fatalf("expected success on third attempt, got %q after %d calls", reply, calls)
+	}
+	if len(delays) != 2 || delays[0] != time.Second || delays[1] != time.Second {
+		t.Fatalf("expected Retry-After delays, got %v", delays)
+	}
+}
+
+func TestCompleteDoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "bad model", http.StatusBadRequest)
+	}, types.ProviderConfig{MaxRetries: retries(5)})
+
+	_, err := client.Complete(context.Background(), "prompt")
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Fatalf("expected 400 error, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected a single attempt, got %d", calls)
+	}
+}
+
+func TestCompleteGivesUpAfterMaxRetries(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "down", http.StatusBadGateway)
+	}, types.ProviderConfig{MaxRetries: retries(2)})
+
+	if _, err := client.Complete(context.Background(), "prompt"); err == nil {
+		t.Fatalf("expected error after retries")
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestCompleteWithRetriesOff(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "down", http.StatusBadGateway)
+	}, types.ProviderConfig{MaxRetries: retries(0)})
+
+	if _, err := client.Complete(context.Background(), "prompt"); err == nil {
+		t.Fatalf("expected error")
+	}
+	if calls != 1 {
+		t.Fatalf("expected a single attempt with maxRetries 0, got %d", calls)
+	}
+}
+
+func TestCompleteStopsBackoffOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		http.Error(w, "down", http.StatusBadGateway)
+	}, types.ProviderConfig{})
+	client.sleep = sleepContext
+	client.backoff = time.Hour
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Complete(ctx, "prompt")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatalf("expected an error after cancelling")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected cancelling to interrupt the backoff")
+	}
+}
+
+func TestNewClientValidation(t *testing.T) {
+	if _, err := NewClient(nil); err != ErrNotConfigured {
+		t.Fatalf("expected ErrNotConfigured, got %v", err)
+	}
+	if _, err := NewClient(&types.ProviderConfig{Model: "m"}); err == nil {
+		t.Fatalf("expected error for missing base URL")
+	}
+	if _, err := NewClient(&types.ProviderConfig{BaseURL: "http://localhost"}); err == nil {
+		t.Fatalf("expected error for missing model")
+	}
+	if _, err := NewClient(&types.ProviderConfig{BaseURL: "http://localhost", Model: "m", APIKeyEnv: "CTX_TEST_UNSET_KEY"}); err == nil {
+		t.Fatalf("expected error for unset API key variable")
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	if Concurrency(nil) != DefaultConcurrency || MaxRetries(&types.ProviderConfig{}) != DefaultMaxRetries {
+		t.Fatalf("expected defaults for unset values")
+	}
+	if Concurrency(&types.ProviderConfig{Concurrency: 8}) != 8 {
+		t.Fatalf("expected configured concurrency")
+	}
+}
@@ -0,0 +1,164 @@
+package scanner
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+const (
+	// sniffSize is how much of each file is read to classify its content.
+	sniffSize = 8 << 10
+	// minifiedLineLength is the line length above which a script or stylesheet is treated as minified.
+	minifiedLineLength = 500
+)
+
+var (
+	goGeneratedHeader = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+	generatedMarker   = regexp.MustCompile(`(?m)^\s*(?://|#|/?\*)\s*@generated\b`)
+
+	protobufPatterns = []string{
+		"*.pb.go",
+		"*.pb.gw.go",
+		"*_pb2.py",
+		"*_pb2_grpc.py",
+		"*_pb.js",
+		"*_pb.ts",
+		"*_pb.d.ts",
+		"*.pb.ts",
+	}
+
+	protobufHeader = regexp.MustCompile(`(?m)^(?://|#)\s*(?:Code generated by protoc-gen-|Generated by the protocol buffer compiler)`)
+
+	minifiableExtensions = map[string]struct{}{
+		".js":  {},
+		".mjs": {},
+		".cjs": {},
+		".css": {},
+	}
+)
+
+// Classify reports why the file name in fsys, which matches the scan filters, should stay out of the index,
+// or "" to keep it. Files above cfg.MaxFileSize and binary files are always skipped; generated, protobuf and
+// minified files are kept when cfg.IncludeGenerated is set.
+func Classify(fsys fs.FS, name string, size int64, cfg types.Config) (types.SkipReason, error) {
+	if reason, ok := classifyByName(name, size, cfg); ok {
+		return reason, nil
+	}
+	head, err := readHead(fsys, name)
+	if err != nil {
+		return "", err
+	}
+	return contentReason(sniff(path.Base(name), head), cfg), nil
+}
+
+// ContentCache remembers what the scanner found in the start of each file, so an unchanged file is not read
+// on every scan. Keys are paths relative to the scan root; an entry only counts while info still matches.
+type ContentCache interface {
+	Classification(key string, info fs.FileInfo) (string, bool)
+	SetClassification(key string, info fs.FileInfo, reason string)
+}
+
+// classifyCached is Classify reusing the content classification cache holds for key.
+func classifyCached(fsys fs.FS, name, key string, info fs.FileInfo, cfg types.Config, cache ContentCache) (types.SkipReason, error) {
+	if cache == nil {
+		return Classify(fsys, name, info.Size(), cfg)
+	}
+	if reason, ok := classifyByName(name, info.Size(), cfg); ok {
+		return reason, nil
+	}
+
+	content, ok := cache.Classification(key, info)
+	if !ok {
+		head, err := readHead(fsys, name)
+		if err != nil {
+			return "", err
+		}
+		content = string(sniff(path.Base(name), head))
+		cache.SetClassification(key, info, content)
+	}
+	return contentReason(types.SkipReason(content), cfg), nil
+}
+
+// classifyByName applies the checks that need no file content.
+func classifyByName(name string, size int64, cfg types.Config) (types.SkipReason, bool) {
+	if cfg.MaxFileSize > 0 && size > cfg.MaxFileSize {
+		return types.SkipTooLarge, true
+	}
+	if !cfg.IncludeGenerated && matchesAny(path.Base(name), protobufPatterns) {
+		return types.SkipProtobuf, true
+	}
+	return "", false
+}
+
+// contentReason applies cfg to what sniff found: only binary files stay out when IncludeGenerated is set.
+func contentReason(content types.SkipReason, cfg types.Config) types.SkipReason {
+	if cfg.IncludeGenerated && content != types.SkipBinary {
+		return ""
+	}
+	return content
+}
+
+// sniff classifies the start of a file regardless of configuration. The first match wins, so a binary file
+// reports binary even when IncludeGenerated would keep it otherwise.
+func sniff(base string, head []byte) types.SkipReason {
+	switch {
+	case bytes.IndexByte(head, 0) >= 0:
+		return types.SkipBinary
+	case protobufHeader.Match(head):
+		return types.SkipProtobuf
+	case goGeneratedHeader.Match(head) || generatedMarker.Match(head):
+		return types.SkipGenerated
+	case isMinified(base, head):
+		return types.SkipMinified
+	}
+	return ""
+}
+
+func readHead(fsys fs.FS, name string) ([]byte, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	head := make([]byte, sniffSize)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	return head[:n], nil
+}
+
+func isMinified(base string, head []byte) bool {
+	ext := strings.ToLower(path.Ext(base))
+	if _, ok := minifiableExtensions[ext]; !ok {
+		return false
+	}
+	if strings.Contains(strings.ToLower(base), ".min.") {
+		return true
+	}
+
+	for _, line := range bytes.Split(head, []byte("\n")) {
+		if len(line) > minifiedLineLength {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := doublestar.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
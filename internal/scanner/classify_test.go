@@ -0,0 +1,100 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dakshpareek/ctx/internal/hash"
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		cfg     types.Config
+		want    types.SkipReason
+	}{
+		{name: "plain go", path: "main.go", content: "package main\n"},
+		{name: "go generated header", path: "mock.go", content: "// Code generated by MockGen. DO NOT EDIT.\n\npackage mocks\n", want: types.SkipGenerated},
+		{name: "generated marker", path: "schema.ts", content: "/**\n * @generated\n */\nexport {};\n", want: types.SkipGenerated},
+		{name: "generated mention in code", path: "scan.go", content: "package scan\n\nvar marker = \"@generated\"\n"},
+		{name: "protobuf by name", path: "api/user.pb.go", content: "package api\n", want: types.SkipProtobuf},
+		{name: "python protobuf", path: "user_pb2.py", content: "x = 1\n", want: types.SkipProtobuf},
+		{name: "protobuf by header", path: "user.ts", content: "// Code generated by protoc-gen-ts. DO NOT EDIT.\n", want: types.SkipProtobuf},
+		{name: "minified by name", path: "vendor.min.js", content: "var a=1;\n", want: types.SkipMinified},
+		{name: "minified by line length", path: "bundle.js", content: strings.Repeat("a=1;", 200), want: types.SkipMinified},
+		{name: "long line in go", path: "table.go", content: "package table\n\nvar s = \"" + strings.Repeat("x", 800) + "\"\n"},
+		{name: "binary", path: "blob.ts", content: "abc\x00def", want: types.SkipBinary},
+		{name: "too large", path: "big.go", content: "package big\n" + strings.Repeat("// pad\n", 20), cfg: types.Config{MaxFileSize: 64}, want: types.SkipTooLarge},
+		{name: "include generated", path: "api/user.pb.go", content: "package api\n", cfg: types.Config{IncludeGenerated: true}},
+		{name: "include generated keeps binary out", path: "blob.ts", content: "\x00", cfg: types.Config{IncludeGenerated: true}, want: types.SkipBinary},
+	}
+
+	root := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeFile(t, root, tt.path, tt.content)
+			got, err := Classify(os.DirFS(root), tt.path, int64(len(tt.content)), tt.cfg)
+			if err != nil {
+				t.Fatalf("Classify error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Classify(%s) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanCachedSkipsReadingUnchangedFiles(t *testing.T) {
+	root := t.TempDir()
+	modTime := time.Now().Add(-time.Hour)
+	write := func(content string) {
+		writeFile(t, root, "mock.go", content)
+		if err := os.Chtimes(filepath.Join(root, "mock.go"), modTime, modTime); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+	cfg := types.Config{IncludedExtensions: []string{".go"}, RootPath: root}
+	cache := hash.LoadCache(filepath.Join(t.TempDir(), hash.CacheFileName))
+
+	write("// @generated\npackage mocks\n")
+	result, err := ScanCached(cfg, 0, cache)
+	if err != nil {
+		t.Fatalf("ScanCached error: %v", err)
+	}
+	if result.Skipped["mock.go"] != types.SkipGenerated {
+		t.Fatalf("expected mock.go skipped as generated, got %#v", result.Skipped)
+	}
+
+	// Same size and mtime: the cached classification is trusted without reading the file.
+	write("// by a human\npackage mocks\n")
+	if result, err = ScanCached(cfg, 0, cache); err != nil {
+		t.Fatalf("ScanCached error: %v", err)
+	}
+	if result.Skipped["mock.go"] != types.SkipGenerated {
+		t.Fatalf("expected the cached classification, got %#v", result.Skipped)
+	}
+
+	// The classification ignores configuration, so IncludeGenerated applies without a rescan.
+	cfg.IncludeGenerated = true
+	if result, err = ScanCached(cfg, 0, cache); err != nil {
+		t.Fatalf("ScanCached error: %v", err)
+	}
+	if len(result.Files) != 1 {
+		t.Fatalf("expected mock.go included with IncludeGenerated, got %#v", result.Files)
+	}
+
+	cfg.IncludeGenerated = false
+	cache.Paranoid = true
+	if result, err = ScanCached(cfg, 0, cache); err != nil {
+		t.Fatalf("ScanCached error: %v", err)
+	}
+	if len(result.Skipped) != 0 || len(result.Files) != 1 {
+		t.Fatalf("expected paranoid mode to read the file again, got %#v", result)
+	}
+}
@@ -0,0 +1,112 @@
+package scanner
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+// DefaultFileTypeRules returns the built-in classification rules in the order they are tried.
+func DefaultFileTypeRules() []types.FileTypeRule {
+	return []types.FileTypeRule{
+		{Type: "service", Paths: []string{"*service.ts", "*service.js", "*service.go"}},
+		{Type: "controller", Paths: []string{"*controller.ts", "*controller.js", "*handler.go"}},
+		{Type: "repository", Paths: []string{"*repository.ts", "*repo.ts", "*repository.go"}},
+		{Type: "dto", Paths: []string{"*dto.ts", "*dto.go", "*/dto/*"}},
+		{Type: "model", Paths: []string{"*model.ts", "*entity.ts", "*model.go"}},
+		{Type: "util", Paths: []string{"*util.ts", "*utils.ts", "*helper.ts"}},
+		{Type: "middleware", Paths: []string{"*middleware.ts", "*middleware.go"}},
+		{Type: "config", Paths: []string{"*config.ts", "*config.go"}},
+	}
+}
+
+var defaultTypeRules = mustCompileTypeRules(DefaultFileTypeRules())
+
+// TypeRules classifies files with ordered rules; the first matching rule wins.
+type TypeRules struct {
+	rules []typeRule
+}
+
+type typeRule struct {
+	fileType string
+	paths    []string
+	content  *regexp.Regexp
+}
+
+// CompileTypeRules validates rules and prepares their globs and regular expressions.
+func CompileTypeRules(rules []types.FileTypeRule) (*TypeRules, error) {
+	compiled := &TypeRules{rules: make([]typeRule, 0, len(rules))}
+	for i, rule := range rules {
+		if rule.Type == "" {
+			return nil, fmt.Errorf("file type rule %d: missing type", i+1)
+		}
+
+		for _, pattern := range rule.Paths {
+			if !doublestar.ValidatePattern(pattern) {
+				return nil, fmt.Errorf("file type rule %q: invalid path glob %q", rule.Type, pattern)
+			}
+		}
+
+		next := typeRule{fileType: rule.Type, paths: rule.Paths}
+		if rule.Content != "" {
+			re, err := regexp.Compile(rule.Content)
+			if err != nil {
+				return nil, fmt.Errorf("file type rule %q: invalid content pattern: %w", rule.Type, err)
+			}
+			next.content = re
+		}
+		compiled.rules = append(compiled.rules, next)
+	}
+	return compiled, nil
+}
+
+func mustCompileTypeRules(rules []types.FileTypeRule) *TypeRules {
+	compiled, err := CompileTypeRules(rules)
+	if err != nil {
+		panic(err)
+	}
+	return compiled
+}
+
+// Detect returns the type of the file at rel, a slash-separated path in fsys, or "" when no rule matches.
+// The file is read only when a rule's paths match and it has a content pattern; unreadable files fail those rules,
+// as do all files when fsys is nil.
+func (r *TypeRules) Detect(fsys fs.FS, rel string) string {
+	var (
+		content []byte
+		loaded  bool
+	)
+
+	for _, rule := range r.rules {
+		if len(rule.paths) > 0 && !matchesPathOrBase(rel, rule.paths) {
+			continue
+		}
+		if rule.content == nil {
+			return rule.fileType
+		}
+
+		if !loaded && fsys != nil {
+			content, _ = fs.ReadFile(fsys, rel)
+			loaded = true
+		}
+		if rule.content.Match(content) {
+			return rule.fileType
+		}
+	}
+	return ""
+}
+
+// DetectFileType classifies a path with the default rules.
+func DetectFileType(p string) string {
+	return defaultTypeRules.Detect(nil, filepath.ToSlash(p))
+}
+
+func matchesPathOrBase(p string, patterns []string) bool {
+	return matchesAny(p, patterns) || matchesAny(path.Base(p), patterns)
+}
@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"os"
+	"testing"
+
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+func TestTypeRulesFirstMatchWins(t *testing.T) {
+	rules, err := CompileTypeRules(DefaultFileTypeRules())
+	if err != nil {
+		t.Fatalf("CompileTypeRules error: %v", err)
+	}
+
+	// Matches both the service and dto rules; the earlier service rule must win every time.
+	for i := 0; i < 50; i++ {
+		if got := rules.Detect(nil, "api/dto/user.service.ts"); got != "service" {
+			t.Fatalf("expected service, got %q", got)
+		}
+	}
+
+	rules, err = CompileTypeRules([]types.FileTypeRule{
+		{Type: "handler", Paths: []string{"*handler.go"}},
+		{Type: "service", Paths: []string{"*service.go", "*handler.go"}},
+	})
+	if err != nil {
+		t.Fatalf("CompileTypeRules error: %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		if got := rules.Detect(nil, "api/user_service_handler.go"); got != "handler" {
+			t.Fatalf("expected first rule to win, got %q", got)
+		}
+	}
+}
+
+func TestTypeRulesContentPatterns(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "web/users.ts", "@Controller('users')\nexport class Users {}\n")
+	writeFile(t, root, "web/orders.ts", "export class Orders {}\n")
+	writeFile(t, root, "cmd/main.go", "package main\n\nfunc main() {}\n")
+
+	rules, err := CompileTypeRules([]types.FileTypeRule{
+		{Type: "controller", Paths: []string{"web/**"}, Content: `@Controller\(`},
+		{Type: "entrypoint", Content: `(?m)^func main\(\)`},
+		{Type: "frontend", Paths: []string{"web/**"}},
+	})
+	if err != nil {
+		t.Fatalf("CompileTypeRules error: %v", err)
+	}
+
+	tests := map[string]string{
+		"web/users.ts":   "controller",
+		"web/orders.ts":  "frontend",
+		"cmd/main.go":    "entrypoint",
+		"lib/missing.go": "",
+	}
+	for path, expected := range tests {
+		if got := rules.Detect(os.DirFS(root), path); got != expected {
+			t.Fatalf("Detect(%q) = %q, expected %q", path, got, expected)
+		}
+	}
+}
+
+func TestCompileTypeRulesRejectsInvalidRules(t *testing.T) {
+	invalid := [][]types.FileTypeRule{
+		{{Paths: []string{"*.go"}}},
+		{{Type: "bad", Paths: []string{"[a-"}}},
+		{{Type: "bad", Content: "("}},
+	}
+	for i, rules := range invalid {
+		if _, err := CompileTypeRules(rules); err == nil {
+			t.Fatalf("case %d: expected error", i)
+		}
+	}
+}
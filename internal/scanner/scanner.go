@@ -1,8 +1,8 @@
 package scanner
 
 import (
-	"fmt"
 	"io/fs"
+	"os"
 	"path"
 	"path/filepath"
 	"sort"
@@ -10,132 +10,348 @@ import (
 
 	"github.com/bmatcuk/doublestar/v4"
 
+	"github.com/dakshpareek/ctx/internal/ignore"
 	"github.com/dakshpareek/ctx/internal/types"
+	"github.com/dakshpareek/ctx/internal/workers"
 )
 
-var fileTypePatterns = map[string][]string{
-	"service": {
-		"*service.ts",
-		"*service.js",
-		"*service.go",
-	},
-	"controller": {
-		"*controller.ts",
-		"*controller.js",
-		"*handler.go",
-	},
-	"repository": {
-		"*repository.ts",
-		"*repo.ts",
-		"*repository.go",
-	},
-	"dto": {
-		"*dto.ts",
-		"*dto.go",
-		"*/dto/*",
-	},
-	"model": {
-		"*model.ts",
-		"*entity.ts",
-		"*model.go",
-	},
-	"util": {
-		"*util.ts",
-		"*utils.ts",
-		"*helper.ts",
-	},
-	"middleware": {
-		"*middleware.ts",
-		"*middleware.go",
-	},
-	"config": {
-		"*config.ts",
-		"*config.go",
-	},
-}
-
 // ScanFiles walks the configured root directory and returns matching files.
+// Unless disabled in config, files ignored by .gitignore files (at any depth, including directories between the
+// repository top and the root) or .git/info/exclude are skipped.
+// .ctxignore files are always honored, and when IncludedPaths is set only files under a matching glob are kept.
 func ScanFiles(cfg types.Config) ([]string, error) {
+	return ScanFilesParallel(cfg, 0)
+}
+
+// ScanFilesParallel is ScanFiles reading up to jobs directories at a time.
+func ScanFilesParallel(cfg types.Config, jobs int) ([]string, error) {
+	result, err := Scan(cfg, jobs)
+	if err != nil {
+		return nil, err
+	}
+	return result.Files, nil
+}
+
+// Result lists the files to index plus the files that matched the filters but were classified away.
+// When packages are configured, Packages maps each indexed file to the root of the package it belongs to.
+type Result struct {
+	Files    []string
+	Skipped  map[string]types.SkipReason
+	Packages map[string]string
+}
+
+// Scan walks the configured root reading up to jobs directories at a time and classifies each matching file.
+// Directories are processed level by level, so the result and the reported error do not depend on scheduling.
+// With cfg.Packages set, only files under a package root are returned, each filtered by its package's settings.
+func Scan(cfg types.Config, jobs int) (Result, error) {
+	return ScanCached(cfg, jobs, nil)
+}
+
+// ScanCached is Scan reading only files that cache has no classification for.
+func ScanCached(cfg types.Config, jobs int, cache ContentCache) (Result, error) {
 	root := cfg.RootPath
 	if root == "" {
 		root = "."
 	}
 	root = filepath.Clean(root)
 
-	var files []string
+	if _, err := os.Stat(root); err != nil {
+		return Result{}, err
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return Result{}, err
+	}
 
-	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
-		if walkErr != nil {
-			return walkErr
-		}
+	top := repositoryTop(abs)
+	rel, err := filepath.Rel(top, abs)
+	if err != nil {
+		return Result{}, err
+	}
+	return scanTree(os.DirFS(top), filepath.ToSlash(rel), cfg, jobs, cache)
+}
 
-		rel, err := filepath.Rel(root, path)
+// ScanTree is Scan over fsys, whose top is the top of the repository: the working tree, or a git revision.
+// Root is the slash-separated path of the scan root in fsys, "." for the top; cfg.RootPath is not consulted.
+func ScanTree(fsys fs.FS, root string, cfg types.Config, jobs int) (Result, error) {
+	return scanTree(fsys, root, cfg, jobs, nil)
+}
+
+func scanTree(fsys fs.FS, root string, cfg types.Config, jobs int, cache ContentCache) (Result, error) {
+	var result Result
+
+	start := scanDir{}
+	if len(cfg.Packages) == 0 {
+		start.pkg = &scanPackage{cfg: cfg}
+	}
+	var err error
+	start.ignore, err = ancestorIgnores(fsys, root, cfg)
+	if err != nil {
+		return result, err
+	}
+
+	level := []scanDir{start}
+	for len(level) > 0 {
+		listings, err := workers.Map(level, jobs, func(dir scanDir) (dirListing, error) {
+			return listDir(fsys, root, dir, cfg, cache)
+		})
 		if err != nil {
-			return fmt.Errorf("relative path: %w", err)
+			return result, err
 		}
-		if rel == "." {
-			return nil
+
+		level = nil
+		for _, listing := range listings {
+			result.Files = append(result.Files, listing.files...)
+			level = append(level, listing.dirs...)
+			for _, skipped := range listing.skipped {
+				if result.Skipped == nil {
+					result.Skipped = make(map[string]types.SkipReason)
+				}
+				result.Skipped[skipped.path] = skipped.reason
+			}
+			if len(cfg.Packages) == 0 {
+				continue
+			}
+			for _, file := range listing.files {
+				if result.Packages == nil {
+					result.Packages = make(map[string]string)
+				}
+				result.Packages[file] = listing.pkg
+			}
 		}
+	}
 
-		normalized := filepath.ToSlash(rel)
+	sort.Strings(result.Files)
+	return result, nil
+}
 
-		excluded, err := isExcluded(normalized, cfg.ExcludedPaths)
-		if err != nil {
-			return err
+// repositoryTop returns the closest directory at or above abs that contains .git, or abs outside a repository.
+func repositoryTop(abs string) string {
+	for top := abs; ; {
+		if _, err := os.Stat(filepath.Join(top, ".git")); err == nil {
+			return top
+		}
+		parent := filepath.Dir(top)
+		if parent == top {
+			return abs
 		}
+		top = parent
+	}
+}
 
-		if excluded {
-			if d.IsDir() {
-				return filepath.SkipDir
+// ancestorIgnores returns the rules that apply to root before its own ignore files are read: the repository's
+// .git/info/exclude, plus the .gitignore and .ctxignore files in the directories between the top of fsys and root.
+func ancestorIgnores(fsys fs.FS, root string, cfg types.Config) (*ignore.Matcher, error) {
+	var segments []string
+	if root != "." {
+		segments = strings.Split(root, "/")
+	}
+
+	var matcher *ignore.Matcher
+	if cfg.GitignoreEnabled() {
+		if info, err := fs.Stat(fsys, ".git"); err == nil && info.IsDir() {
+			patterns, err := ignore.ReadAncestorFile(fsys, strings.Join(segments, "/"), ".git/info/exclude")
+			if err != nil {
+				return nil, err
 			}
-			return nil
+			matcher = matcher.With(patterns)
 		}
+	}
 
-		if d.IsDir() {
-			return nil
+	dir := "."
+	for i, segment := range segments {
+		prefix := strings.Join(segments[i:], "/")
+		for _, name := range ignoreFileNames(cfg) {
+			patterns, err := ignore.ReadAncestorFile(fsys, prefix, path.Join(dir, name))
+			if err != nil {
+				return nil, err
+			}
+			matcher = matcher.With(patterns)
 		}
+		dir = path.Join(dir, segment)
+	}
+	return matcher, nil
+}
 
-		if !shouldIncludeByExtension(normalized, cfg.IncludedExtensions) {
-			return nil
-		}
+// ignoreFileNames lists the per-directory ignore files to read, in the order their rules apply.
+func ignoreFileNames(cfg types.Config) []string {
+	if cfg.GitignoreEnabled() {
+		return []string{ignore.GitignoreFileName, ignore.CtxignoreFileName}
+	}
+	return []string{ignore.CtxignoreFileName}
+}
 
-		files = append(files, normalized)
-		return nil
-	})
-	if err != nil {
-		return nil, err
+// scanDir is a directory waiting to be listed, with the ignore rules inherited from its parents.
+// pkg is nil for directories outside every package, which are only walked to reach package roots.
+type scanDir struct {
+	path   string
+	ignore *ignore.Matcher
+	pkg    *scanPackage
+}
+
+// scanPackage is a package root and the configuration its files are filtered with.
+// The root is empty when the whole tree is a single package.
+type scanPackage struct {
+	root string
+	cfg  types.Config
+}
+
+// relative returns p relative to the package root, which is how package settings are matched.
+func (p *scanPackage) relative(normalized string) string {
+	if p.root == "" {
+		return normalized
 	}
+	return strings.TrimPrefix(normalized, p.root+"/")
+}
 
-	sort.Strings(files)
-	return files, nil
+// dirListing holds the included files, the skipped files and the subdirectories to descend into for one directory.
+type dirListing struct {
+	files   []string
+	skipped []skippedFile
+	dirs    []scanDir
+	pkg     string
 }
 
-// DetectFileType returns the inferred file classification based on known patterns.
-func DetectFileType(p string) string {
-	normalized := filepath.ToSlash(p)
-	base := path.Base(normalized)
-	for fileType, patterns := range fileTypePatterns {
-		for _, pattern := range patterns {
-			matched, err := doublestar.Match(pattern, normalized)
-			if err != nil {
-				continue
-			}
-			if matched {
-				return fileType
-			}
+type skippedFile struct {
+	path   string
+	reason types.SkipReason
+}
 
-			matchedBase, err := doublestar.Match(pattern, base)
-			if err != nil {
-				continue
+func listDir(fsys fs.FS, root string, dir scanDir, cfg types.Config, cache ContentCache) (dirListing, error) {
+	var listing dirListing
+
+	fullDir := path.Join(root, dir.path)
+	entries, err := fs.ReadDir(fsys, fullDir)
+	if err != nil {
+		return listing, err
+	}
+
+	matcher := dir.ignore
+	for _, name := range ignoreFileNames(cfg) {
+		patterns, err := ignore.ReadFile(fsys, dir.path, path.Join(fullDir, name))
+		if err != nil {
+			return listing, err
+		}
+		matcher = matcher.With(patterns)
+	}
+
+	if dir.pkg == nil {
+		return listOutsidePackages(dir, entries, matcher, cfg)
+	}
+
+	pkg := dir.pkg
+	listing.pkg = pkg.root
+	for _, entry := range entries {
+		normalized := entry.Name()
+		if dir.path != "" {
+			normalized = dir.path + "/" + normalized
+		}
+		rel := pkg.relative(normalized)
+
+		excluded, err := isExcluded(rel, pkg.cfg.ExcludedPaths)
+		if err != nil {
+			return listing, err
+		}
+		if excluded || matcher.Match(normalized, entry.IsDir()) {
+			continue
+		}
+
+		if entry.IsDir() {
+			if mayContainIncluded(rel, pkg.cfg.IncludedPaths) {
+				listing.dirs = append(listing.dirs, scanDir{path: normalized, ignore: matcher, pkg: pkg})
 			}
-			if matchedBase {
-				return fileType
+			continue
+		}
+
+		if !shouldIncludeByExtension(normalized, pkg.cfg.IncludedExtensions) || !isIncluded(rel, pkg.cfg.IncludedPaths) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return listing, err
+		}
+		reason, err := classifyCached(fsys, path.Join(fullDir, entry.Name()), normalized, info, pkg.cfg, cache)
+		if err != nil {
+			return listing, err
+		}
+		if reason != "" {
+			listing.skipped = append(listing.skipped, skippedFile{path: normalized, reason: reason})
+			continue
+		}
+		listing.files = append(listing.files, normalized)
+	}
+
+	return listing, nil
+}
+
+// listOutsidePackages descends from a directory outside every package towards the configured package roots.
+// Files here are not indexed; top-level excludes and ignore files still prune the walk.
+func listOutsidePackages(dir scanDir, entries []fs.DirEntry, matcher *ignore.Matcher, cfg types.Config) (dirListing, error) {
+	var (
+		listing dirListing
+		roots   []string
+	)
+	for _, pkg := range cfg.Packages {
+		roots = append(roots, pkg.Roots...)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		normalized := entry.Name()
+		if dir.path != "" {
+			normalized = dir.path + "/" + normalized
+		}
+
+		excluded, err := isExcluded(normalized, cfg.ExcludedPaths)
+		if err != nil {
+			return listing, err
+		}
+		if excluded || matcher.Match(normalized, true) {
+			continue
+		}
+
+		if pkg, ok := matchPackage(normalized, cfg); ok {
+			listing.dirs = append(listing.dirs, scanDir{path: normalized, ignore: matcher, pkg: &scanPackage{root: normalized, cfg: pkg}})
+		} else if mayContainIncluded(normalized, roots) {
+			listing.dirs = append(listing.dirs, scanDir{path: normalized, ignore: matcher})
+		}
+	}
+
+	return listing, nil
+}
+
+// matchPackage returns the configuration for the first package whose roots match dir.
+func matchPackage(dir string, cfg types.Config) (types.Config, bool) {
+	for _, pkg := range cfg.Packages {
+		for _, pattern := range pkg.Roots {
+			if matched, _ := doublestar.Match(strings.Trim(pattern, "/"), dir); matched {
+				return PackageConfig(cfg, pkg), true
 			}
 		}
 	}
-	return ""
+	return types.Config{}, false
+}
+
+// PackageConfig returns the settings used for files in pkg: its own extensions when set, the top-level
+// excludes plus its own, and only its own IncludedPaths, since the top-level globs are relative to the project.
+func PackageConfig(cfg types.Config, pkg types.PackageConfig) types.Config {
+	merged := cfg
+	merged.Packages = nil
+	merged.IncludedPaths = pkg.IncludedPaths
+	if pkg.IncludedExtensions != nil {
+		merged.IncludedExtensions = pkg.IncludedExtensions
+	}
+	if len(pkg.ExcludedPaths) > 0 {
+		merged.ExcludedPaths = append(append([]string{}, cfg.ExcludedPaths...), pkg.ExcludedPaths...)
+	}
+	return merged
 }
 
+// isExcluded matches path against ExcludedPaths. A pattern matches the whole path or any single segment,
+// except that a leading slash anchors it to the scan root: "/test" excludes only the top-level test directory.
 func isExcluded(path string, patterns []string) (bool, error) {
 	if len(patterns) == 0 {
 		return false, nil
@@ -147,6 +363,17 @@ func isExcluded(path string, patterns []string) (bool, error) {
 			continue
 		}
 
+		if anchored, ok := strings.CutPrefix(pattern, "/"); ok {
+			matched, err := doublestar.Match(strings.TrimSuffix(anchored, "/"), path)
+			if err != nil {
+				return false, err
+			}
+			if matched {
+				return true, nil
+			}
+			continue
+		}
+
 		matched, err := doublestar.Match(pattern, path)
 		if err != nil {
 			return false, err
@@ -173,6 +400,56 @@ func isExcluded(path string, patterns []string) (bool, error) {
 	return false, nil
 }
 
+// isIncluded reports whether a file, or one of its parent directories, matches an IncludedPaths glob.
+// An empty list includes everything.
+func isIncluded(p string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for candidate := p; candidate != "." && candidate != "/"; candidate = path.Dir(candidate) {
+		for _, pattern := range patterns {
+			if matched, _ := doublestar.Match(strings.Trim(pattern, "/"), candidate); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mayContainIncluded reports whether a directory can hold files matched by IncludedPaths,
+// so the scanner skips unrelated subtrees instead of walking them.
+func mayContainIncluded(dir string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	dirSegments := strings.Split(dir, "/")
+	for _, pattern := range patterns {
+		pattern = strings.Trim(pattern, "/")
+		if strings.ContainsAny(pattern, "{}") {
+			// Alternatives may contain slashes, so segment-wise matching is not reliable.
+			return true
+		}
+
+		patternSegments := strings.Split(pattern, "/")
+		possible := true
+		for i, segment := range dirSegments {
+			if i >= len(patternSegments) || patternSegments[i] == "**" {
+				break
+			}
+			if matched, _ := doublestar.Match(patternSegments[i], segment); !matched {
+				possible = false
+				break
+			}
+		}
+		if possible {
+			return true
+		}
+	}
+	return false
+}
+
 func shouldIncludeByExtension(path string, extensions []string) bool {
 	if len(extensions) == 0 {
 		return true
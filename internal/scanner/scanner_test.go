@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"testing/fstest"
 
 	"github.com/dakshpareek/ctx/internal/types"
 )
@@ -49,6 +50,272 @@ func TestScanFilesRespectsConfig(t *testing.T) {
 	}
 }
 
+func TestScanFilesParallelMatchesSequential(t *testing.T) {
+	root := t.TempDir()
+
+	for _, dir := range []string{"a", "b/c", "b/d/e", "f", "vendor/lib"} {
+		for _, name := range []string{"one.go", "two.go", "skip.txt"} {
+			makeFile(t, root, filepath.Join(dir, name))
+		}
+	}
+	makeFile(t, root, "main.go")
+
+	cfg := types.Config{
+		IncludedExtensions: []string{".go"},
+		ExcludedPaths:      []string{"vendor"},
+		RootPath:           root,
+	}
+
+	sequential, err := ScanFilesParallel(cfg, 1)
+	if err != nil {
+		t.Fatalf("ScanFilesParallel(1) error: %v", err)
+	}
+	if len(sequential) != 9 {
+		t.Fatalf("expected 9 files, got %#v", sequential)
+	}
+
+	for _, jobs := range []int{2, 8} {
+		files, err := ScanFilesParallel(cfg, jobs)
+		if err != nil {
+			t.Fatalf("ScanFilesParallel(%d) error: %v", jobs, err)
+		}
+		if !reflect.DeepEqual(files, sequential) {
+			t.Fatalf("jobs=%d: expected %#v, got %#v", jobs, sequential, files)
+		}
+	}
+}
+
+func TestScanFilesHonorsGitignore(t *testing.T) {
+	root := t.TempDir()
+
+	makeFile(t, root, "main.go")
+	makeFile(t, root, "api/types_gen.go")
+	makeFile(t, root, "api/keep_gen.go")
+	makeFile(t, root, "gen/client.go")
+	makeFile(t, root, "web/src/app.ts")
+	makeFile(t, root, "web/src/out/bundle.ts")
+	makeFile(t, root, "web/out/bundle.ts")
+	makeFile(t, root, "local/scratch.go")
+	writeFile(t, root, ".gitignore", "*_gen.go\n!keep_gen.go\n/gen/\n")
+	writeFile(t, root, "web/.gitignore", "/out\n")
+	writeFile(t, root, ".git/info/exclude", "local/\n")
+
+	cfg := types.Config{
+		IncludedExtensions: []string{".ts", ".go"},
+		RootPath:           root,
+	}
+
+	files, err := ScanFiles(cfg)
+	if err != nil {
+		t.Fatalf("ScanFiles error: %v", err)
+	}
+
+	expected := []string{
+		"api/keep_gen.go",
+		"main.go",
+		"web/src/app.ts",
+		"web/src/out/bundle.ts",
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Fatalf("expected files %#v, got %#v", expected, files)
+	}
+
+	disabled := false
+	cfg.RespectGitignore = &disabled
+	files, err = ScanFiles(cfg)
+	if err != nil {
+		t.Fatalf("ScanFiles error: %v", err)
+	}
+	if len(files) != 8 {
+		t.Fatalf("expected gitignore to be skipped when disabled, got %#v", files)
+	}
+}
+
+func TestScanFilesHonorsIgnoreFilesAboveRoot(t *testing.T) {
+	repo := t.TempDir()
+
+	makeFile(t, repo, "src/main.go")
+	makeFile(t, repo, "src/api/types_gen.go")
+	makeFile(t, repo, "src/build/out.go")
+	makeFile(t, repo, "src/legacy/old.go")
+	makeFile(t, repo, "src/scratch/notes.go")
+	writeFile(t, repo, ".gitignore", "*_gen.go\n/build/\n")
+	writeFile(t, repo, ".ctxignore", "src/legacy/\n")
+	writeFile(t, repo, ".git/info/exclude", "scratch/\n")
+
+	cfg := types.Config{
+		IncludedExtensions: []string{".go"},
+		RootPath:           filepath.Join(repo, "src"),
+	}
+
+	files, err := ScanFiles(cfg)
+	if err != nil {
+		t.Fatalf("ScanFiles error: %v", err)
+	}
+
+	expected := []string{
+		"build/out.go",
+		"main.go",
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Fatalf("expected files %#v, got %#v", expected, files)
+	}
+}
+
+func TestScanTreeReadsIgnoreFilesFromTree(t *testing.T) {
+	tree := fstest.MapFS{
+		".gitignore":             {Data: []byte("*_gen.go\n")},
+		"src/.ctxignore":         {Data: []byte("legacy/\n")},
+		"src/main.go":            {Data: []byte("package main\n")},
+		"src/api/types_gen.go":   {Data: []byte("package api\n")},
+		"src/legacy/old.go":      {Data: []byte("package legacy\n")},
+		"src/assets/logo.go":     {Data: []byte("\x00")},
+		"docs/readme.go":         {Data: []byte("package docs\n")},
+		"src/internal/worker.go": {Data: []byte("package internal\n")},
+	}
+
+	result, err := ScanTree(tree, "src", types.Config{IncludedExtensions: []string{".go"}}, 0)
+	if err != nil {
+		t.Fatalf("ScanTree error: %v", err)
+	}
+
+	expected := []string{"internal/worker.go", "main.go"}
+	if !reflect.DeepEqual(result.Files, expected) {
+		t.Fatalf("expected files %#v, got %#v", expected, result.Files)
+	}
+	if result.Skipped["assets/logo.go"] != types.SkipBinary {
+		t.Fatalf("expected binary file skipped, got %v", result.Skipped)
+	}
+}
+
+func TestScanFilesCtxignoreAndIncludedPaths(t *testing.T) {
+	root := t.TempDir()
+
+	makeFile(t, root, "services/user/user.go")
+	makeFile(t, root, "services/user/mock_user.go")
+	makeFile(t, root, "services/test/fixtures.go")
+	makeFile(t, root, "services/api/generated.go")
+	makeFile(t, root, "libs/core/core.go")
+	makeFile(t, root, "libs/extra/extra.go")
+	makeFile(t, root, "test/e2e.go")
+	makeFile(t, root, "main.go")
+	writeFile(t, root, ".gitignore", "generated.go\n")
+	writeFile(t, root, ".ctxignore", "mock_*.go\n!services/api/generated.go\n")
+
+	cfg := types.Config{
+		IncludedExtensions: []string{".go"},
+		IncludedPaths:      []string{"services/**", "libs/core"},
+		RootPath:           root,
+	}
+
+	files, err := ScanFiles(cfg)
+	if err != nil {
+		t.Fatalf("ScanFiles error: %v", err)
+	}
+
+	expected := []string{
+		"libs/core/core.go",
+		"services/api/generated.go",
+		"services/test/fixtures.go",
+		"services/user/user.go",
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Fatalf("expected files %#v, got %#v", expected, files)
+	}
+
+	cfg.IncludedPaths = nil
+	cfg.ExcludedPaths = []string{"/test"}
+	files, err = ScanFiles(cfg)
+	if err != nil {
+		t.Fatalf("ScanFiles error: %v", err)
+	}
+
+	expected = []string{
+		"libs/core/core.go",
+		"libs/extra/extra.go",
+		"main.go",
+		"services/api/generated.go",
+		"services/test/fixtures.go",
+		"services/user/user.go",
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Fatalf("expected anchored exclude to skip only the top-level test directory, got %#v", files)
+	}
+}
+
+func TestScanPackages(t *testing.T) {
+	root := t.TempDir()
+
+	makeFile(t, root, "services/api/main.go")
+	makeFile(t, root, "services/api/web/app.ts")
+	makeFile(t, root, "services/api/test/api_test_helpers.go")
+	makeFile(t, root, "services/billing/billing.go")
+	makeFile(t, root, "packages/ui/button.tsx")
+	makeFile(t, root, "packages/ui/src/test/render.tsx")
+	makeFile(t, root, "packages/ui/tools/build.go")
+	makeFile(t, root, "scripts/release.go")
+	makeFile(t, root, "root.go")
+	writeFile(t, root, ".gitignore", "billing.go\n")
+
+	cfg := types.Config{
+		IncludedExtensions: []string{".go", ".ts", ".tsx"},
+		ExcludedPaths:      []string{"node_modules"},
+		RootPath:           root,
+		Packages: []types.PackageConfig{
+			{Roots: []string{"services/*"}, IncludedExtensions: []string{".go"}, ExcludedPaths: []string{"/test"}},
+			{Roots: []string{"packages/*"}, IncludedExtensions: []string{".ts", ".tsx"}},
+		},
+	}
+
+	result, err := Scan(cfg, 2)
+	if err != nil {
+		t.Fatalf("Scan error: %v", err)
+	}
+
+	expectedFiles := []string{
+		"packages/ui/button.tsx",
+		"packages/ui/src/test/render.tsx",
+		"services/api/main.go",
+	}
+	if !reflect.DeepEqual(result.Files, expectedFiles) {
+		t.Fatalf("expected files %#v, got %#v", expectedFiles, result.Files)
+	}
+
+	expectedPackages := map[string]string{
+		"packages/ui/button.tsx":          "packages/ui",
+		"packages/ui/src/test/render.tsx": "packages/ui",
+		"services/api/main.go":            "services/api",
+	}
+	if !reflect.DeepEqual(result.Packages, expectedPackages) {
+		t.Fatalf("expected packages %#v, got %#v", expectedPackages, result.Packages)
+	}
+}
+
+func TestMayContainIncluded(t *testing.T) {
+	patterns := []string{"services/*/api/**", "libs/core"}
+
+	tests := []struct {
+		dir  string
+		want bool
+	}{
+		{"services", true},
+		{"services/user", true},
+		{"services/user/api", true},
+		{"services/user/api/v1", true},
+		{"services/user/db", false},
+		{"libs", true},
+		{"libs/core/internal", true},
+		{"libs/extra", false},
+		{"web", false},
+	}
+
+	for _, tt := range tests {
+		if got := mayContainIncluded(tt.dir, patterns); got != tt.want {
+			t.Errorf("mayContainIncluded(%q) = %v, want %v", tt.dir, got, tt.want)
+		}
+	}
+}
+
 func TestScanFilesNonexistentRoot(t *testing.T) {
 	cfg := types.Config{
 		IncludedExtensions: []string{".go"},
@@ -92,3 +359,15 @@ func makeFile(t *testing.T, root, rel string) {
 		t.Fatalf("failed to write file %q: %v", path, err)
 	}
 }
+
+func writeFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+
+	path := filepath.Join(root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("failed to create directories for %q: %v", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write file %q: %v", path, err)
+	}
+}
@@ -0,0 +1,104 @@
+package skeleton
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// Extractor produces a skeleton for a source file without an AI round-trip.
+type Extractor interface {
+	Extract(sourcePath string, src []byte) (string, error)
+}
+
+// ExtractorFunc adapts a plain function to the Extractor interface.
+type ExtractorFunc func(sourcePath string, src []byte) (string, error)
+
+// Extract calls f(sourcePath, src).
+func (f ExtractorFunc) Extract(sourcePath string, src []byte) (string, error) {
+	return f(sourcePath, src)
+}
+
+// Registry maps file extensions (".go") and detected file types ("dto") to extractors.
+type Registry struct {
+	extractors map[string]Extractor
+}
+
+// NewRegistry returns an empty extractor registry.
+func NewRegistry() *Registry {
+	return &Registry{extractors: make(map[string]Extractor)}
+}
+
+// DefaultRegistry returns a registry pre-populated with the built-in extractors.
+func DefaultRegistry() *Registry {
+	registry := NewRegistry()
+	registry.Register(".go", ExtractorFunc(ExtractGo))
+	return registry
+}
+
+// Register associates an extractor with an extension (leading dot) or a file type, replacing any previous entry.
+func (r *Registry) Register(key string, extractor Extractor) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if key == "" || extractor == nil {
+		return
+	}
+	r.extractors[key] = extractor
+}
+
+// Lookup returns the extractor for a file. A file type registration wins over an extension registration.
+func (r *Registry) Lookup(sourcePath, fileType string) (Extractor, bool) {
+	if r == nil {
+		return nil, false
+	}
+
+	if fileType != "" {
+		if extractor, ok := r.extractors[strings.ToLower(fileType)]; ok {
+			return extractor, true
+		}
+	}
+
+	ext := strings.ToLower(path.Ext(strings.ReplaceAll(sourcePath, "\\", "/")))
+	if ext == "" {
+		return nil, false
+	}
+	extractor, ok := r.extractors[ext]
+	return extractor, ok
+}
+
+// CommandExtractor runs an external program that reads source on stdin and writes the skeleton to stdout.
+// The source path relative to the project root is exposed through the CTX_SOURCE_PATH environment variable.
+type CommandExtractor struct {
+	Command string
+}
+
+// Extract runs the configured command for the given source.
+func (c CommandExtractor) Extract(sourcePath string, src []byte) (string, error) {
+	fields := strings.Fields(c.Command)
+	if len(fields) == 0 {
+		return "", errors.New("extractor command is empty")
+	}
+
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Env = append(os.Environ(), "CTX_SOURCE_PATH="+sourcePath)
+	cmd.Stdin = bytes.NewReader(src)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("run extractor %q: %w: %s", fields[0], err, msg)
+		}
+		return "", fmt.Errorf("run extractor %q: %w", fields[0], err)
+	}
+	if len(bytes.TrimSpace(output)) == 0 {
+		return "", fmt.Errorf("extractor %q produced no output", fields[0])
+	}
+
+	return string(output), nil
+}
@@ -0,0 +1,65 @@
+package skeleton
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRegistryLookup(t *testing.T) {
+	registry := DefaultRegistry()
+
+	if _, ok := registry.Lookup("src/app.go", ""); !ok {
+		t.Fatalf("expected built-in Go extractor")
+	}
+	if _, ok := registry.Lookup("src/app.ts", "service"); ok {
+		t.Fatalf("expected no extractor for TypeScript by default")
+	}
+
+	dto := ExtractorFunc(func(string, []byte) (string, error) { return "dto", nil })
+	registry.Register("DTO", dto)
+	registry.Register(".TS", ExtractorFunc(func(string, []byte) (string, error) { return "ts", nil }))
+
+	extractor, ok := registry.Lookup("src/user.dto.ts", "dto")
+	if !ok {
+		t.Fatalf("expected dto extractor")
+	}
+	if got, _ := extractor.Extract("src/user.dto.ts", nil); got != "dto" {
+		t.Fatalf("expected file type registration to win, got %q", got)
+	}
+
+	extractor, ok = registry.Lookup("src/app.TS", "")
+	if !ok {
+		t.Fatalf("expected extension lookup to be case-insensitive")
+	}
+	if got, _ := extractor.Extract("src/app.ts", nil); got != "ts" {
+		t.Fatalf("expected ts extractor, got %q", got)
+	}
+
+	var nilRegistry *Registry
+	if _, ok := nilRegistry.Lookup("src/app.go", ""); ok {
+		t.Fatalf("expected nil registry to have no extractors")
+	}
+}
+
+func TestCommandExtractor(t *testing.T) {
+	if _, err := exec.LookPath("tr"); err != nil {
+		t.Skip("tr not available")
+	}
+
+	extractor := CommandExtractor{Command: "tr a-z A-Z"}
+	output, err := extractor.Extract("src/app.rs", []byte("fn main() {}\n"))
+	if err != nil {
+		t.Fatalf("Extract error: %v", err)
+	}
+	if strings.TrimSpace(output) != "FN MAIN() {}" {
+		t.Fatalf("unexpected extractor output %q", output)
+	}
+
+	if _, err := (CommandExtractor{}).Extract("src/app.rs", nil); err == nil {
+		t.Fatalf("expected error for empty command")
+	}
+	if _, err := (CommandExtractor{Command: "tr a-z A-Z"}).Extract("src/app.rs", nil); err == nil {
+		t.Fatalf("expected error for empty output")
+	}
+}
@@ -0,0 +1,198 @@
+package skeleton
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// ExtractGo parses Go source and renders a skeleton following the sections of the default prompt template.
+// The path is the source path relative to the project root and is used for the File line.
+func ExtractGo(sourcePath string, src []byte) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, sourcePath, src, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("parse %s: %w", sourcePath, err)
+	}
+
+	sourcePath = strings.ReplaceAll(sourcePath, "\\", "/")
+
+	var (
+		stdImports   []string
+		otherImports []string
+		state        []string
+		constructors []string
+		public       []string
+		private      []string
+	)
+
+	for _, spec := range file.Imports {
+		importPath := strings.Trim(spec.Path.Value, "`\"")
+		if isStdlibImport(importPath) {
+			stdImports = append(stdImports, "`"+importPath+"`")
+		} else {
+			otherImports = append(otherImports, "`"+importPath+"`")
+		}
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			state = append(state, describeGenDecl(d)...)
+		case *ast.FuncDecl:
+			line := fmt.Sprintf("`%s`", funcSignature(fset, d))
+			if summary := docSummary(d.Doc); summary != "" {
+				line += " — " + summary
+			}
+
+			switch {
+			case isConstructor(d):
+				constructors = append(constructors, line)
+			case ast.IsExported(d.Name.Name):
+				public = append(public, line)
+			default:
+				private = append(private, line)
+			}
+		}
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("**%s** (package %s)\n", path.Base(sourcePath), file.Name.Name))
+	builder.WriteString(fmt.Sprintf("- File: `%s:1`\n", sourcePath))
+	builder.WriteString(fmt.Sprintf("- Imports: %s\n", describeImports(stdImports, otherImports)))
+	if len(state) == 0 {
+		builder.WriteString("- State: None\n")
+	} else {
+		builder.WriteString(fmt.Sprintf("- State: %s\n", strings.Join(state, "; ")))
+	}
+	if len(constructors) > 0 {
+		builder.WriteString(fmt.Sprintf("- Constructor: %s\n", strings.Join(constructors, "; ")))
+	}
+
+	if len(public) > 0 {
+		builder.WriteString("\n**Public Methods**\n")
+		for _, line := range public {
+			builder.WriteString("- Method: " + line + "\n")
+		}
+	}
+
+	if len(private) > 0 {
+		builder.WriteString("\n**Private Helpers**\n")
+		for _, line := range private {
+			builder.WriteString("- Helper: " + line + "\n")
+		}
+	}
+
+	if len(otherImports) > 0 {
+		builder.WriteString("\n**Key Dependencies**\n")
+		for _, imp := range otherImports {
+			builder.WriteString("- " + imp + "\n")
+		}
+	}
+
+	return builder.String(), nil
+}
+
+func describeImports(std, other []string) string {
+	switch {
+	case len(std) == 0 && len(other) == 0:
+		return "None"
+	case len(other) == 0:
+		return "standard library " + strings.Join(std, ", ")
+	case len(std) == 0:
+		return "external " + strings.Join(other, ", ")
+	default:
+		return fmt.Sprintf("standard library %s; external %s", strings.Join(std, ", "), strings.Join(other, ", "))
+	}
+}
+
+func describeGenDecl(decl *ast.GenDecl) []string {
+	var items []string
+	for _, spec := range decl.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			items = append(items, fmt.Sprintf("`type %s %s`", s.Name.Name, typeKind(s)))
+		case *ast.ValueSpec:
+			keyword := "var"
+			if decl.Tok == token.CONST {
+				keyword = "const"
+			}
+			for _, name := range s.Names {
+				if name.Name == "_" {
+					continue
+				}
+				items = append(items, fmt.Sprintf("`%s %s`", keyword, name.Name))
+			}
+		}
+	}
+	return items
+}
+
+func typeKind(spec *ast.TypeSpec) string {
+	if spec.Assign.IsValid() {
+		return "alias"
+	}
+	switch spec.Type.(type) {
+	case *ast.StructType:
+		return "struct"
+	case *ast.InterfaceType:
+		return "interface"
+	case *ast.FuncType:
+		return "func"
+	case *ast.MapType:
+		return "map"
+	case *ast.ArrayType:
+		return "slice"
+	default:
+		return "defined"
+	}
+}
+
+func funcSignature(fset *token.FileSet, decl *ast.FuncDecl) string {
+	stripped := &ast.FuncDecl{
+		Recv: decl.Recv,
+		Name: decl.Name,
+		Type: decl.Type,
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, stripped); err != nil {
+		return "func " + decl.Name.Name
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+func isConstructor(decl *ast.FuncDecl) bool {
+	if decl.Recv != nil || !strings.HasPrefix(decl.Name.Name, "New") {
+		return false
+	}
+	return decl.Type.Results != nil && len(decl.Type.Results.List) > 0
+}
+
+func docSummary(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+	text := strings.Join(strings.Fields(group.Text()), " ")
+	if idx := strings.Index(text, ". "); idx >= 0 {
+		text = text[:idx+1]
+	}
+	return text
+}
+
+func isStdlibImport(importPath string) bool {
+	first := importPath
+	if idx := strings.IndexRune(first, '/'); idx >= 0 {
+		first = first[:idx]
+	}
+	return !strings.ContainsFunc(first, func(r rune) bool {
+		return r == '.' || unicode.IsUpper(r)
+	})
+}
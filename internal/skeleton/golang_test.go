@@ -0,0 +1,85 @@
+package skeleton
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleGoSource = `package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/spf13/cobra"
+)
+
+// DefaultPort is the port used when none is configured.
+const DefaultPort = 8080
+
+// Server wraps an HTTP listener.
+type Server struct {
+	addr string
+}
+
+// NewServer constructs a Server bound to addr.
+func NewServer(addr string) *Server {
+	return &Server{addr: addr}
+}
+
+// Start runs the server until the context is cancelled. It blocks.
+func (s *Server) Start(ctx context.Context) error {
+	return s.listen(ctx)
+}
+
+func (s *Server) listen(ctx context.Context) error {
+	_ = http.ListenAndServe
+	_ = cobra.Command{}
+	return nil
+}
+`
+
+func TestExtractGo(t *testing.T) {
+	output, err := ExtractGo("internal/server/server.go", []byte(sampleGoSource))
+	if err != nil {
+		t.Fatalf("ExtractGo error: %v", err)
+	}
+
+	expected := []string{
+		"**server.go** (package server)",
+		"- File: `internal/server/server.go:1`",
+		"- Imports: standard library `context`, `net/http`; external `github.com/spf13/cobra`",
+		"- State: `const DefaultPort`; `type Server struct`",
+		"- Constructor: `func NewServer(addr string) *Server` — NewServer constructs a Server bound to addr.",
+		"**Public Methods**",
+		"- Method: `func (s *Server) Start(ctx context.Context) error` — Start runs the server until the context is cancelled.",
+		"**Private Helpers**",
+		"- Helper: `func (s *Server) listen(ctx context.Context) error`",
+		"**Key Dependencies**",
+	}
+
+	for _, want := range expected {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected output to contain %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestExtractGoStateless(t *testing.T) {
+	output, err := ExtractGo("main.go", []byte("package main\n\nfunc main() {}\n"))
+	if err != nil {
+		t.Fatalf("ExtractGo error: %v", err)
+	}
+	if !strings.Contains(output, "- Imports: None") || !strings.Contains(output, "- State: None") {
+		t.Fatalf("expected empty imports and state:\n%s", output)
+	}
+	if strings.Contains(output, "**Public Methods**") {
+		t.Fatalf("expected no public methods section:\n%s", output)
+	}
+}
+
+func TestExtractGoInvalidSource(t *testing.T) {
+	if _, err := ExtractGo("broken.go", []byte("package main\nfunc {")); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
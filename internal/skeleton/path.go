@@ -8,6 +8,8 @@ import (
 const (
 	// DirRoot is the root directory used to store skeleton files.
 	DirRoot = ".ctx/skeletons"
+	// SnapshotDirRoot stores copies of source files as they were when their skeleton became current.
+	SnapshotDirRoot = ".ctx/snapshots"
 )
 
 // PathForSource returns the relative skeleton path corresponding to a source file path.
@@ -26,3 +28,23 @@ func PathForSource(source string) string {
 
 	return builder.String()
 }
+
+// SnapshotPathForSource returns the relative snapshot path corresponding to a source file path.
+func SnapshotPathForSource(source string) string {
+	source = strings.ReplaceAll(source, "\\", "/")
+	return SnapshotDirRoot + "/" + filepath.ToSlash(source)
+}
+
+// RewriteSourcePath points a skeleton's "File:" line at newPath after its source moved from oldPath.
+// Other lines are left alone, since descriptions may mention the old name on purpose.
+func RewriteSourcePath(content, oldPath, newPath string) string {
+	lines := strings.SplitAfter(content, "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(strings.TrimLeft(line, "-* \t"), "File:") {
+			continue
+		}
+		lines[i] = strings.Replace(line, oldPath, newPath, 1)
+		break
+	}
+	return strings.Join(lines, "")
+}
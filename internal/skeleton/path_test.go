@@ -19,3 +19,28 @@ func TestPathForSource(t *testing.T) {
 		}
 	}
 }
+
+func TestSnapshotPathForSource(t *testing.T) {
+	tests := map[string]string{
+		"src/app/service.go":    ".ctx/snapshots/src/app/service.go",
+		"src\\windows\\path.go": ".ctx/snapshots/src/windows/path.go",
+	}
+
+	for input, expected := range tests {
+		if got := SnapshotPathForSource(input); got != expected {
+			t.Fatalf("SnapshotPathForSource(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+func TestRewriteSourcePath(t *testing.T) {
+	content := "**user.go** (package api)\n- File: `api/user.go:1`\n- Imports: mirrors api/user.go in v1\n"
+	expected := "**user.go** (package api)\n- File: `users/user.go:1`\n- Imports: mirrors api/user.go in v1\n"
+	if got := RewriteSourcePath(content, "api/user.go", "users/user.go"); got != expected {
+		t.Fatalf("RewriteSourcePath = %q, expected %q", got, expected)
+	}
+
+	if got := RewriteSourcePath("**x**\n- Imports: none\n", "a.go", "b.go"); got != "**x**\n- Imports: none\n" {
+		t.Fatalf("expected content without a File line unchanged, got %q", got)
+	}
+}
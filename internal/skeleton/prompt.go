@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
 
 	"github.com/dakshpareek/ctx/internal/types"
 )
@@ -14,6 +17,10 @@ import _ "embed"
 const (
 	// PromptFileName is the filename used within .ctx for the prompt template.
 	PromptFileName = "skeleton-prompt.txt"
+	// PromptDirName is the directory within .ctx holding per-type and per-extension templates.
+	PromptDirName = "prompts"
+	// DefaultTemplateName names the template used when no override matches a file.
+	DefaultTemplateName = "default"
 )
 
 //go:embed default_prompt.txt
@@ -30,34 +37,173 @@ func DefaultPrompt() string {
 //  2. Workspace default at .ctx/skeleton-prompt.txt
 //  3. Embedded default prompt
 func LoadPromptTemplate(cfg types.Config) (string, error) {
-	baseDir := filepath.Dir(DirRoot) // .ctx
-
-	candidates := []string{
-		filepath.Clean(filepath.Join(baseDir, PromptFileName)),
+	for _, dir := range workspaceDirs(cfg) {
+		data, err := os.ReadFile(filepath.Join(dir, PromptFileName))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return "", fmt.Errorf("read prompt template: %w", err)
+		}
+		return string(data), nil
 	}
 
-	if root := cfg.RootPath; root != "" && root != "." {
-		candidates = append([]string{filepath.Clean(filepath.Join(root, baseDir, PromptFileName))}, candidates...)
+	return DefaultPrompt(), nil
+}
+
+// PromptTemplates resolves the prompt template for each file from the overrides in .ctx/prompts,
+// and carries the outer scaffold the file sections are rendered into.
+type PromptTemplates struct {
+	Default   string
+	Scaffold  string
+	overrides map[string]string
+	packages  []packagePrompt
+}
+
+// packagePrompt routes files under any of roots to the override named key.
+type packagePrompt struct {
+	roots []string
+	key   string
+}
+
+// NewPromptTemplates builds a template set from a default and overrides keyed by file type or extension.
+// The scaffold starts as the built-in default.
+func NewPromptTemplates(defaultTemplate string, overrides map[string]string) *PromptTemplates {
+	set := &PromptTemplates{Default: defaultTemplate, Scaffold: DefaultScaffold(), overrides: make(map[string]string, len(overrides))}
+	for key, template := range overrides {
+		set.overrides[strings.ToLower(key)] = template
 	}
+	return set
+}
 
-	// Legacy fallback for pre-rename workspaces.
-	candidates = append(candidates, filepath.Clean(filepath.Join(".spine", PromptFileName)))
-	if root := cfg.RootPath; root != "" && root != "." {
-		candidates = append(candidates, filepath.Clean(filepath.Join(root, ".spine", PromptFileName)))
+// LoadPromptTemplates loads the default template plus every <key>.txt file in a prompts directory.
+// Keys are file types (service, dto, ...) or extensions without the dot (go, ts, ...). Directories
+// follow the same preference order as LoadPromptTemplate.
+func LoadPromptTemplates(cfg types.Config) (*PromptTemplates, error) {
+	defaultTemplate, err := LoadPromptTemplate(cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, candidate := range uniqueStrings(candidates) {
-		data, err := os.ReadFile(candidate)
+	overrides := make(map[string]string)
+	for _, dir := range workspaceDirs(cfg) {
+		entries, err := os.ReadDir(filepath.Join(dir, PromptDirName))
 		if err != nil {
 			if errors.Is(err, os.ErrNotExist) {
 				continue
 			}
-			return "", fmt.Errorf("read prompt template: %w", err)
+			return nil, fmt.Errorf("read prompt templates: %w", err)
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".txt" {
+				continue
+			}
+			key := strings.ToLower(strings.TrimSuffix(entry.Name(), ".txt"))
+			if _, ok := overrides[key]; ok {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dir, PromptDirName, entry.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("read prompt template: %w", err)
+			}
+			overrides[key] = string(data)
 		}
-		return string(data), nil
 	}
 
-	return DefaultPrompt(), nil
+	set := NewPromptTemplates(defaultTemplate, overrides)
+	if err := set.AddPackages(cfg.Packages); err != nil {
+		return nil, err
+	}
+	set.Scaffold, err = LoadScaffold(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// AddPackages routes files under each package's roots to the override named by its Prompt.
+// Packages without a prompt are skipped; a prompt with no matching override is an error.
+func (p *PromptTemplates) AddPackages(packages []types.PackageConfig) error {
+	for _, pkg := range packages {
+		if pkg.Prompt == "" {
+			continue
+		}
+		key := strings.ToLower(pkg.Prompt)
+		if _, ok := p.overrides[key]; !ok {
+			return fmt.Errorf("package prompt %q: no %s/%s.txt template found", pkg.Prompt, PromptDirName, key)
+		}
+		p.packages = append(p.packages, packagePrompt{roots: pkg.Roots, key: key})
+	}
+	return nil
+}
+
+// Lookup returns the template name and content for a file. A package prompt wins over a file-type
+// override, which wins over an extension override; otherwise the default template is used.
+func (p *PromptTemplates) Lookup(path, fileType string) (string, string) {
+	for _, pkg := range p.packages {
+		for _, root := range pkg.roots {
+			if underRoot(path, strings.Trim(root, "/")) {
+				return pkg.key, p.overrides[pkg.key]
+			}
+		}
+	}
+	if fileType != "" {
+		if template, ok := p.overrides[strings.ToLower(fileType)]; ok {
+			return strings.ToLower(fileType), template
+		}
+	}
+	if ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."); ext != "" {
+		if template, ok := p.overrides[ext]; ok {
+			return ext, template
+		}
+	}
+	return DefaultTemplateName, p.Default
+}
+
+// Unparsed returns the parse error of every template that RenderTemplate will use verbatim, keyed by name.
+func (p *PromptTemplates) Unparsed() map[string]error {
+	unparsed := make(map[string]error)
+	if err := CheckTemplate(DefaultTemplateName, p.Default); err != nil {
+		unparsed[DefaultTemplateName] = err
+	}
+	for key, template := range p.overrides {
+		if err := CheckTemplate(key, template); err != nil {
+			unparsed[key] = err
+		}
+	}
+	return unparsed
+}
+
+// underRoot reports whether one of path's parent directories matches the root glob.
+func underRoot(path, root string) bool {
+	for dir := filepath.ToSlash(filepath.Dir(path)); dir != "." && dir != "/"; dir = filepath.ToSlash(filepath.Dir(dir)) {
+		if matched, _ := doublestar.Match(root, dir); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// workspaceDirs returns the workspace directories searched for templates, most specific first.
+func workspaceDirs(cfg types.Config) []string {
+	baseDir := filepath.Dir(DirRoot) // .ctx
+
+	candidates := []string{
+		filepath.Clean(baseDir),
+	}
+
+	if root := cfg.RootPath; root != "" && root != "." {
+		candidates = append([]string{filepath.Clean(filepath.Join(root, baseDir))}, candidates...)
+	}
+
+	// Legacy fallback for pre-rename workspaces.
+	candidates = append(candidates, ".spine")
+	if root := cfg.RootPath; root != "" && root != "." {
+		candidates = append(candidates, filepath.Clean(filepath.Join(root, ".spine")))
+	}
+
+	return uniqueStrings(candidates)
 }
 
 func uniqueStrings(values []string) []string {
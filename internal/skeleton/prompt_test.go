@@ -101,3 +101,70 @@ func withWorkingDir(t *testing.T, dir string) {
 		_ = os.Chdir(orig)
 	})
 }
+
+func TestLoadPromptTemplatesOverrides(t *testing.T) {
+	tempDir := t.TempDir()
+	withWorkingDir(t, tempDir)
+
+	promptDir := filepath.Join(".ctx", PromptDirName)
+	if err := os.MkdirAll(promptDir, 0o755); err != nil {
+		t.Fatalf("failed to create prompt dir: %v", err)
+	}
+	for name, content := range map[string]string{"dto.txt": "dto prompt", "Go.txt": "go prompt", "notes.md": "ignored"} {
+		if err := os.WriteFile(filepath.Join(promptDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	templates, err := LoadPromptTemplates(types.Config{})
+	if err != nil {
+		t.Fatalf("LoadPromptTemplates error: %v", err)
+	}
+
+	tests := []struct {
+		path, fileType, name, template string
+	}{
+		{"api/user.dto.go", "dto", "dto", "dto prompt"},
+		{"api/user.handler.go", "controller", "go", "go prompt"},
+		{"web/app.ts", "", DefaultTemplateName, DefaultPrompt()},
+		{"docs/notes.md", "", DefaultTemplateName, DefaultPrompt()},
+	}
+	for _, tt := range tests {
+		name, template := templates.Lookup(tt.path, tt.fileType)
+		if name != tt.name || template != tt.template {
+			t.Fatalf("Lookup(%q, %q) = %q, %q; expected %q, %q", tt.path, tt.fileType, name, template, tt.name, tt.template)
+		}
+	}
+}
+
+func TestPromptTemplatesPackagePrompts(t *testing.T) {
+	templates := NewPromptTemplates("default prompt", map[string]string{
+		"go-service": "service prompt",
+		"dto":        "dto prompt",
+	})
+	err := templates.AddPackages([]types.PackageConfig{
+		{Roots: []string{"services/*"}, Prompt: "Go-Service"},
+		{Roots: []string{"packages/*"}},
+	})
+	if err != nil {
+		t.Fatalf("AddPackages error: %v", err)
+	}
+
+	tests := []struct {
+		path, fileType, name string
+	}{
+		{"services/api/user.dto.go", "dto", "go-service"},
+		{"packages/ui/user.dto.ts", "dto", "dto"},
+		{"packages/ui/button.tsx", "", DefaultTemplateName},
+		{"services/readme.go", "", DefaultTemplateName},
+	}
+	for _, tt := range tests {
+		if name, _ := templates.Lookup(tt.path, tt.fileType); name != tt.name {
+			t.Fatalf("Lookup(%q, %q) = %q; expected %q", tt.path, tt.fileType, name, tt.name)
+		}
+	}
+
+	if err := templates.AddPackages([]types.PackageConfig{{Roots: []string{"apps/*"}, Prompt: "missing"}}); err == nil {
+		t.Fatalf("expected error for a package prompt without a template")
+	}
+}
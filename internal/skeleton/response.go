@@ -0,0 +1,123 @@
+package skeleton
+
+import (
+	"regexp"
+	"strings"
+)
+
+// PathMarker prefixes the skeleton path line emitted in prompts and expected in AI responses.
+const PathMarker = "**Skeleton Path:**"
+
+var markerPattern = regexp.MustCompile(`^\s*(?:[#>*-]+\s*)?\*\*Skeleton Path:\*\*\s*(.+?)\s*$`)
+
+// ResponseBlock is a single skeleton extracted from an AI response.
+type ResponseBlock struct {
+	SkeletonPath string
+	Content      string
+}
+
+// ParseResponse splits an AI response into skeleton blocks keyed by their Skeleton Path markers.
+// When a block contains a fenced code block, only the fenced content is kept.
+func ParseResponse(text string) []ResponseBlock {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var (
+		blocks  []ResponseBlock
+		current *ResponseBlock
+		body    []string
+	)
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.Content = blockContent(body)
+		blocks = append(blocks, *current)
+	}
+
+	for _, line := range lines {
+		if match := markerPattern.FindStringSubmatch(line); match != nil {
+			flush()
+			current = &ResponseBlock{SkeletonPath: normalizeMarkerPath(match[1])}
+			body = nil
+			continue
+		}
+		if current != nil {
+			body = append(body, line)
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+func normalizeMarkerPath(raw string) string {
+	value := strings.Trim(strings.TrimSpace(raw), "`*")
+	value = strings.ReplaceAll(value, "\\", "/")
+	return strings.TrimPrefix(value, "./")
+}
+
+func blockContent(lines []string) string {
+	start := -1
+	var fence string
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if f := fencePrefix(trimmed); f != "" {
+			start = i + 1
+			fence = f
+			break
+		}
+	}
+
+	if start >= 0 {
+		for end := start; end < len(lines); end++ {
+			trimmed := strings.TrimSpace(lines[end])
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				return joinContent(lines[start:end])
+			}
+		}
+		return joinContent(lines[start:])
+	}
+
+	// Unfenced block: drop trailing separators and headings that introduce the next file.
+	end := len(lines)
+	for end > 0 {
+		trimmed := strings.TrimSpace(lines[end-1])
+		if trimmed == "" || trimmed == "---" || strings.HasPrefix(trimmed, "#") {
+			end--
+			continue
+		}
+		break
+	}
+	return joinContent(lines[:end])
+}
+
+func fencePrefix(line string) string {
+	for _, marker := range []string{"`", "~"} {
+		if !strings.HasPrefix(line, marker+marker+marker) {
+			continue
+		}
+		n := 0
+		for n < len(line) && line[n:n+1] == marker {
+			n++
+		}
+		return line[:n]
+	}
+	return ""
+}
+
+func joinContent(lines []string) string {
+	content := strings.Trim(strings.Join(lines, "\n"), "\n")
+	if strings.TrimSpace(content) == "" {
+		return ""
+	}
+	return content + "\n"
+}
+
+// ContentFromReply extracts a single skeleton from a reply that may or may not use Skeleton Path markers.
+func ContentFromReply(text string) string {
+	if blocks := ParseResponse(text); len(blocks) > 0 {
+		return blocks[0].Content
+	}
+	return blockContent(strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n"))
+}
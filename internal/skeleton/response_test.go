@@ -0,0 +1,48 @@
+package skeleton
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseResponseFenced(t *testing.T) {
+	response := "Here you go.\n\n" +
+		"### File 1: src/app.go\n" +
+		"**Skeleton Path:** `.ctx/skeletons/src/app.skeleton.go`\n\n" +
+		"```markdown\n**App**\n- File: `src/app.go:1`\n```\n\n---\n\n" +
+		"### File 2: web/ui.ts\n" +
+		"**Skeleton Path:** ./.ctx/skeletons/web/ui.skeleton.ts\n" +
+		"````\n**UI**\n```ts\nexport {}\n```\n````\n"
+
+	blocks := ParseResponse(response)
+	expected := []ResponseBlock{
+		{SkeletonPath: ".ctx/skeletons/src/app.skeleton.go", Content: "**App**\n- File: `src/app.go:1`\n"},
+		{SkeletonPath: ".ctx/skeletons/web/ui.skeleton.ts", Content: "**UI**\n```ts\nexport {}\n```\n"},
+	}
+	if !reflect.DeepEqual(blocks, expected) {
+		t.Fatalf("expected blocks %#v, got %#v", expected, blocks)
+	}
+}
+
+func TestParseResponseUnfenced(t *testing.T) {
+	response := "**Skeleton Path:** .ctx/skeletons/a.skeleton.go\r\n" +
+		"**A**\r\n- State: None\r\n\r\n---\r\n\r\n### File 2: b.go\r\n" +
+		"- **Skeleton Path:** .ctx/skeletons/b.skeleton.go\r\n\r\n"
+
+	blocks := ParseResponse(response)
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 blocks, got %#v", blocks)
+	}
+	if blocks[0].Content != "**A**\n- State: None\n" {
+		t.Fatalf("unexpected first block content %q", blocks[0].Content)
+	}
+	if blocks[1].SkeletonPath != ".ctx/skeletons/b.skeleton.go" || blocks[1].Content != "" {
+		t.Fatalf("expected empty second block, got %#v", blocks[1])
+	}
+}
+
+func TestParseResponseWithoutMarkers(t *testing.T) {
+	if blocks := ParseResponse("no skeletons here"); len(blocks) != 0 {
+		t.Fatalf("expected no blocks, got %#v", blocks)
+	}
+}
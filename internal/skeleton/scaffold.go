@@ -0,0 +1,125 @@
+package skeleton
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+// ScaffoldFileName is the filename used within .ctx for the outer prompt scaffold.
+const ScaffoldFileName = "prompt-scaffold.tmpl"
+
+//go:embed default_scaffold.tmpl
+var defaultScaffold string
+
+// DefaultScaffold returns the built-in outer prompt scaffold.
+func DefaultScaffold() string {
+	return defaultScaffold
+}
+
+// FileData describes one file to skeleton templates and to the scaffold.
+type FileData struct {
+	Number           int
+	Path             string
+	Type             string
+	Status           string
+	Language         string
+	SkeletonPath     string
+	PreviousSkeleton string
+	Source           string
+	Diff             string
+	Config           types.Config
+}
+
+// PromptGroup is a run of files that share the same rendered skeleton template.
+type PromptGroup struct {
+	Name     string
+	Template string
+	Files    []FileData
+}
+
+// PromptData is the data passed to the scaffold.
+type PromptData struct {
+	PathMarker string
+	Config     types.Config
+	Groups     []PromptGroup
+}
+
+var templateFuncs = template.FuncMap{
+	"fence": fence,
+}
+
+// RenderTemplate executes a skeleton template for one file. Templates without actions, and templates that
+// do not parse (literal JSX or Handlebars braces, for example), are returned as is.
+func RenderTemplate(name, text string, data FileData) (string, error) {
+	if CheckTemplate(name, text) != nil {
+		return text, nil
+	}
+	return execute(name, text, data)
+}
+
+// CheckTemplate reports why a skeleton template containing "{{" does not parse. RenderTemplate uses such
+// templates verbatim, so callers can warn about them once.
+func CheckTemplate(name, text string) error {
+	if !strings.Contains(text, "{{") {
+		return nil
+	}
+	if _, err := template.New(name).Funcs(templateFuncs).Parse(text); err != nil {
+		return fmt.Errorf("parse template %s: %w", name, err)
+	}
+	return nil
+}
+
+// RenderScaffold executes the outer prompt scaffold.
+func RenderScaffold(text string, data PromptData) (string, error) {
+	return execute(ScaffoldFileName, text, data)
+}
+
+// LoadScaffold loads the outer prompt scaffold, preferring a workspace override over the built-in default.
+func LoadScaffold(cfg types.Config) (string, error) {
+	for _, dir := range workspaceDirs(cfg) {
+		data, err := os.ReadFile(filepath.Join(dir, ScaffoldFileName))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return "", fmt.Errorf("read prompt scaffold: %w", err)
+		}
+		return string(data), nil
+	}
+
+	return DefaultScaffold(), nil
+}
+
+func execute(name, text string, data any) (string, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse template %s: %w", name, err)
+	}
+
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, data); err != nil {
+		return "", fmt.Errorf("render template %s: %w", name, err)
+	}
+	return builder.String(), nil
+}
+
+// fence wraps content in a fenced code block, ending with a newline after the closing fence.
+func fence(lang, content string) string {
+	var builder strings.Builder
+	builder.WriteString("```")
+	builder.WriteString(lang)
+	builder.WriteString("\n")
+	builder.WriteString(content)
+	if !strings.HasSuffix(content, "\n") {
+		builder.WriteString("\n")
+	}
+	builder.WriteString("```\n")
+	return builder.String()
+}
@@ -0,0 +1,102 @@
+package skeleton
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dakshpareek/ctx/internal/types"
+)
+
+func TestRenderTemplateVariables(t *testing.T) {
+	data := FileData{
+		Path:     "api/user.dto.ts",
+		Type:     "dto",
+		Language: "typescript",
+		Config:   types.Config{SkeletonPromptVersion: "3.0"},
+	}
+
+	got, err := RenderTemplate("dto", "{{.Type}} {{.Path}} ({{.Language}}) v{{.Config.SkeletonPromptVersion}}", data)
+	if err != nil {
+		t.Fatalf("RenderTemplate error: %v", err)
+	}
+	if want := "dto api/user.dto.ts (typescript) v3.0"; got != want {
+		t.Fatalf("RenderTemplate = %q, expected %q", got, want)
+	}
+
+	if _, err := RenderTemplate("bad", "{{.Missing}}", data); err == nil {
+		t.Fatalf("expected error for unknown field")
+	}
+}
+
+func TestRenderTemplateLiteralBraces(t *testing.T) {
+	text := "Keep props like <Item style={{color: \"red\"}} /> and {{#each items}} as written."
+
+	if err := CheckTemplate("tsx", text); err == nil {
+		t.Fatalf("expected CheckTemplate to report the literal braces")
+	}
+	got, err := RenderTemplate("tsx", text, FileData{Path: "ui/item.tsx"})
+	if err != nil {
+		t.Fatalf("RenderTemplate error: %v", err)
+	}
+	if got != text {
+		t.Fatalf("RenderTemplate = %q, expected the text verbatim", got)
+	}
+
+	templates := NewPromptTemplates("{{.Path}}", map[string]string{"tsx": text})
+	unparsed := templates.Unparsed()
+	if len(unparsed) != 1 || unparsed["tsx"] == nil {
+		t.Fatalf("expected only the tsx template unparsed, got %v", unparsed)
+	}
+}
+
+func TestRenderScaffoldDefault(t *testing.T) {
+	data := PromptData{
+		PathMarker: PathMarker,
+		Groups: []PromptGroup{{
+			Name:     DefaultTemplateName,
+			Template: "template",
+			Files:    []FileData{{Number: 1, Path: "main.go", Status: "missing", Language: "go", SkeletonPath: PathForSource("main.go"), Source: "package main\n"}},
+		}},
+	}
+
+	got, err := RenderScaffold(DefaultScaffold(), data)
+	if err != nil {
+		t.Fatalf("RenderScaffold error: %v", err)
+	}
+	for _, want := range []string{"## Instructions", "```text\ntemplate\n```", "### File 1: main.go", PathMarker + " .ctx/skeletons/main.skeleton.go", "```go\npackage main\n```", "## Index Updates Required"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in scaffold output:\n%s", want, got)
+		}
+	}
+}
+
+func TestLoadScaffoldOverride(t *testing.T) {
+	tempDir := t.TempDir()
+	withWorkingDir(t, tempDir)
+
+	scaffold, err := LoadScaffold(types.Config{})
+	if err != nil {
+		t.Fatalf("LoadScaffold error: %v", err)
+	}
+	if scaffold != DefaultScaffold() {
+		t.Fatalf("expected default scaffold")
+	}
+
+	if err := os.MkdirAll(".ctx", 0o755); err != nil {
+		t.Fatalf("failed to create workspace dir: %v", err)
+	}
+	expected := "{{range .Groups}}{{range .Files}}{{.Path}}{{end}}{{end}}"
+	if err := os.WriteFile(filepath.Join(".ctx", ScaffoldFileName), []byte(expected), 0o644); err != nil {
+		t.Fatalf("failed to write scaffold override: %v", err)
+	}
+
+	templates, err := LoadPromptTemplates(types.Config{})
+	if err != nil {
+		t.Fatalf("LoadPromptTemplates error: %v", err)
+	}
+	if templates.Scaffold != expected {
+		t.Fatalf("expected scaffold override, got %q", templates.Scaffold)
+	}
+}
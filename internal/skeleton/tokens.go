@@ -0,0 +1,13 @@
+package skeleton
+
+import "unicode/utf8"
+
+// charsPerToken approximates how many characters of source or prose map to one model token.
+const charsPerToken = 4
+
+// EstimateTokens returns a rough token count for text using a characters-per-token heuristic.
+// It errs on the high side so batches stay within model limits.
+func EstimateTokens(text string) int {
+	runes := utf8.RuneCountInString(text)
+	return (runes + charsPerToken - 1) / charsPerToken
+}
@@ -0,0 +1,23 @@
+package skeleton
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := map[string]int{
+		"":                      0,
+		"abc":                   1,
+		"abcd":                  1,
+		"abcde":                 2,
+		strings.Repeat("x", 40): 10,
+		"héllo wörld":           3,
+	}
+
+	for input, expected := range tests {
+		if got := EstimateTokens(input); got != expected {
+			t.Fatalf("EstimateTokens(%q) = %d, expected %d", input, got, expected)
+		}
+	}
+}
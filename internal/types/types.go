@@ -12,6 +12,17 @@ const (
 	StatusPendingGeneration Status = "pendingGeneration"
 )
 
+// SkipReason explains why the scanner left a matching file out of the index.
+type SkipReason string
+
+const (
+	SkipGenerated SkipReason = "generated"
+	SkipMinified  SkipReason = "minified"
+	SkipProtobuf  SkipReason = "protobuf"
+	SkipTooLarge  SkipReason = "too large"
+	SkipBinary    SkipReason = "binary"
+)
+
 // ExitCode represents the exit status of the CLI.
 type ExitCode int
 
@@ -50,14 +61,19 @@ func (e *Error) Unwrap() error {
 
 // FileEntry describes a single tracked file within the index.
 type FileEntry struct {
-	Path         string    `json:"path"`
-	Hash         string    `json:"hash"`
-	SkeletonHash string    `json:"skeletonHash"`
-	SkeletonPath string    `json:"skeletonPath"`
-	LastModified time.Time `json:"lastModified"`
-	Status       Status    `json:"status"`
-	Type         string    `json:"type"`
-	Size         int64     `json:"size"`
+	Path               string    `json:"path"`
+	Hash               string    `json:"hash"`
+	SkeletonHash       string    `json:"skeletonHash"`
+	SkeletonPath       string    `json:"skeletonPath"`
+	LastModified       time.Time `json:"lastModified"`
+	Status             Status    `json:"status"`
+	Type               string    `json:"type"`
+	Size               int64     `json:"size"`
+	SourceSnapshotHash string    `json:"sourceSnapshotHash,omitempty"`
+	PromptVersion      string    `json:"promptVersion,omitempty"`
+	TemplateHash       string    `json:"templateHash,omitempty"`
+	StructuralHash     string    `json:"structuralHash,omitempty"`
+	Package            string    `json:"package,omitempty"`
 }
 
 // IndexStats aggregates counts of files by status.
@@ -71,18 +87,64 @@ type IndexStats struct {
 
 // Index is the root structure persisted as index.json.
 type Index struct {
-	Version       string               `json:"version"`
-	PromptVersion string               `json:"promptVersion"`
-	LastSync      time.Time            `json:"lastSync"`
-	Config        Config               `json:"config"`
-	Files         map[string]FileEntry `json:"files"`
-	Stats         IndexStats           `json:"stats"`
+	Version          string                `json:"version"`
+	PromptVersion    string                `json:"promptVersion"`
+	LastSync         time.Time             `json:"lastSync"`
+	LastSyncedCommit string                `json:"lastSyncedCommit,omitempty"`
+	Config           Config                `json:"config"`
+	Files            map[string]FileEntry  `json:"files"`
+	Skipped          map[string]SkipReason `json:"skipped,omitempty"`
+	Stats            IndexStats            `json:"stats"`
+	PackageStats     map[string]IndexStats `json:"packageStats,omitempty"`
 }
 
 // Config captures user configuration for scanning behavior.
 type Config struct {
-	IncludedExtensions    []string `json:"includedExtensions"`
-	ExcludedPaths         []string `json:"excludedPaths"`
-	SkeletonPromptVersion string   `json:"skeletonPromptVersion"`
-	RootPath              string   `json:"rootPath"`
+	IncludedExtensions    []string          `json:"includedExtensions"`
+	IncludedPaths         []string          `json:"includedPaths,omitempty"`
+	ExcludedPaths         []string          `json:"excludedPaths"`
+	SkeletonPromptVersion string            `json:"skeletonPromptVersion"`
+	RootPath              string            `json:"rootPath"`
+	Extractors            map[string]string `json:"extractors,omitempty"`
+	Provider              *ProviderConfig   `json:"provider,omitempty"`
+	SemanticHash          bool              `json:"semanticHash,omitempty"`
+	RespectGitignore      *bool             `json:"respectGitignore,omitempty"`
+	MaxFileSize           int64             `json:"maxFileSize,omitempty"`
+	IncludeGenerated      bool              `json:"includeGenerated,omitempty"`
+	FileTypes             []FileTypeRule    `json:"fileTypes,omitempty"`
+	Packages              []PackageConfig   `json:"packages,omitempty"`
+}
+
+// PackageConfig declares package roots in a monorepo, as directory globs such as "services/*",
+// and the settings that differ for files under them. Paths in a package's lists are relative to the package root.
+// Prompt names a template in .ctx/prompts used for the package's files.
+type PackageConfig struct {
+	Roots              []string `json:"roots"`
+	IncludedExtensions []string `json:"includedExtensions,omitempty"`
+	ExcludedPaths      []string `json:"excludedPaths,omitempty"`
+	IncludedPaths      []string `json:"includedPaths,omitempty"`
+	Prompt             string   `json:"prompt,omitempty"`
+}
+
+// FileTypeRule assigns Type to files matching any of Paths (globs against the path or base name)
+// and, when set, whose content matches the Content regular expression. Rules are tried in order.
+type FileTypeRule struct {
+	Type    string   `json:"type"`
+	Paths   []string `json:"paths,omitempty"`
+	Content string   `json:"content,omitempty"`
+}
+
+// GitignoreEnabled reports whether scanning honors .gitignore files. It defaults to true when unset.
+func (c Config) GitignoreEnabled() bool {
+	return c.RespectGitignore == nil || *c.RespectGitignore
+}
+
+// ProviderConfig describes an OpenAI-compatible chat-completions endpoint used to generate skeletons.
+type ProviderConfig struct {
+	BaseURL        string `json:"baseUrl"`
+	Model          string `json:"model"`
+	APIKeyEnv      string `json:"apiKeyEnv,omitempty"`
+	Concurrency    int    `json:"concurrency,omitempty"`
+	MaxRetries     *int   `json:"maxRetries,omitempty"`
+	TimeoutSeconds int    `json:"timeoutSeconds,omitempty"`
 }
@@ -0,0 +1,51 @@
+// Package workers runs independent per-file work on a bounded pool of goroutines.
+package workers
+
+import (
+	"runtime"
+	"sync"
+)
+
+// DefaultJobs is the pool size used when no --jobs value is given.
+func DefaultJobs() int {
+	return runtime.NumCPU()
+}
+
+// Map calls fn for every item using at most jobs goroutines and returns the results in input order.
+// Every item is processed even when some fail; the returned error is the one for the earliest item,
+// so error reporting does not depend on scheduling. A jobs value below one uses DefaultJobs.
+func Map[T, R any](items []T, jobs int, fn func(T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	errs := make([]error, len(items))
+
+	if jobs < 1 {
+		jobs = DefaultJobs()
+	}
+	if jobs > len(items) {
+		jobs = len(items)
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i], errs[i] = fn(items[i])
+			}
+		}()
+	}
+	for i := range items {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
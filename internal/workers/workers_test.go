@@ -0,0 +1,73 @@
+package workers
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMapPreservesOrder(t *testing.T) {
+	items := make([]int, 50)
+	for i := range items {
+		items[i] = i
+	}
+
+	results, err := Map(items, 8, func(n int) (string, error) {
+		time.Sleep(time.Duration(50-n) * 10 * time.Microsecond)
+		return fmt.Sprint(n * n), nil
+	})
+	if err != nil {
+		t.Fatalf("Map error: %v", err)
+	}
+	for i, got := range results {
+		if want := fmt.Sprint(i * i); got != want {
+			t.Fatalf("results[%d] = %s, expected %s", i, got, want)
+		}
+	}
+}
+
+func TestMapReturnsEarliestError(t *testing.T) {
+	var calls atomic.Int32
+	_, err := Map([]int{0, 1, 2, 3, 4}, 3, func(n int) (int, error) {
+		calls.Add(1)
+		if n == 1 || n == 3 {
+			time.Sleep(time.Duration(4-n) * time.Millisecond)
+			return 0, errors.New(fmt.Sprint("item ", n))
+		}
+		return n, nil
+	})
+	if err == nil || err.Error() != "item 1" {
+		t.Fatalf("expected error for item 1, got %v", err)
+	}
+	if calls.Load() != 5 {
+		t.Fatalf("expected every item to be processed, got %d", calls.Load())
+	}
+}
+
+func TestMapBounds(t *testing.T) {
+	var active, peak atomic.Int32
+	_, err := Map(make([]struct{}, 20), 2, func(struct{}) (struct{}, error) {
+		n := active.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		active.Add(-1)
+		return struct{}{}, nil
+	})
+	if err != nil {
+		t.Fatalf("Map error: %v", err)
+	}
+	if peak.Load() > 2 {
+		t.Fatalf("expected at most 2 concurrent calls, saw %d", peak.Load())
+	}
+
+	if results, err := Map([]int(nil), 0, func(n int) (int, error) { return n, nil }); err != nil || len(results) != 0 {
+		t.Fatalf("expected empty results for no items")
+	}
+}
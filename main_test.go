@@ -23,7 +23,7 @@ func TestGuidedWorkflowEndToEnd(t *testing.T) {
 	withWorkingDir(t, tempDir)
 
 	runCommand(t, "init")
-	runCommand(t, "ask", "--quiet")
+	runCommand(t, "ask", "--no-local", "--quiet")
 
 	if _, err := os.Stat(".ctx/index.json"); err != nil {
 		t.Fatalf("expected index.json to exist: %v", err)